@@ -4,8 +4,12 @@ go 1.21
 
 require (
 	github.com/gdamore/tcell/v2 v2.6.0
+	github.com/mattn/go-runewidth v0.0.15
 	github.com/rivo/tview v0.0.0-20230826224341-9754ab44dc1c
 	github.com/valyala/fasthttp v1.48.0
+	golang.org/x/net v0.14.0
+	golang.org/x/text v0.12.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -13,10 +17,8 @@ require (
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/klauspost/compress v1.16.3 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/rivo/uniseg v0.4.4 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	golang.org/x/sys v0.11.0 // indirect
 	golang.org/x/term v0.11.0 // indirect
-	golang.org/x/text v0.12.0 // indirect
 )
@@ -0,0 +1,365 @@
+package assertion // Package 'assertion' checks responses against the expectations saved with a request
+
+import (
+	"encoding/json" // For showing JSON values
+	"fmt"           // For errors and descriptions
+	"regexp"        // For the 'matches' operator
+	"strconv"       // For numbers and quoted values
+	"strings"       // For parsing and comparing text
+	"time"          // For response times
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being checked
+	"github.com/SiirRandall/go-restful/internal/jsonpath"              // Values picked out of JSON bodies
+)
+
+// Kinds of assertions, the part of the response they check.
+const (
+	KindStatus = "status" // Status code
+	KindHeader = "header" // A response header, named by Target
+	KindJSON   = "json"   // Value a JSONPath or jq expression, held by Target, picks from a JSON body
+	KindBody   = "body"   // Body as text
+	KindTime   = "time"   // Total time of the request, in milliseconds
+)
+
+// Operators comparing the actual value with the expected one.
+const (
+	OpEquals         = "=="
+	OpNotEquals      = "!="
+	OpLess           = "<"
+	OpLessOrEqual    = "<="
+	OpGreater        = ">"
+	OpGreaterOrEqual = ">="
+	OpContains       = "contains"
+	OpNotContains    = "!contains"
+	OpMatches        = "matches" // Regular expression
+	OpExists         = "exists"  // Takes no expected value
+	OpNotExists      = "!exists" // Takes no expected value
+)
+
+// 'operators' lists the operators each kind accepts.
+var operators = map[string][]string{
+	KindStatus: {OpEquals, OpNotEquals, OpLess, OpLessOrEqual, OpGreater, OpGreaterOrEqual},
+	KindHeader: {OpEquals, OpNotEquals, OpContains, OpNotContains, OpMatches, OpExists, OpNotExists},
+	KindJSON: {OpEquals, OpNotEquals, OpLess, OpLessOrEqual, OpGreater, OpGreaterOrEqual,
+		OpContains, OpNotContains, OpMatches, OpExists, OpNotExists},
+	KindBody: {OpEquals, OpNotEquals, OpContains, OpNotContains, OpMatches},
+	KindTime: {OpLess, OpLessOrEqual, OpGreater, OpGreaterOrEqual},
+}
+
+// 'Assertion' is an expectation about a response, such as status == 200 or $.data[0].id exists.
+type Assertion struct {
+	Kind     string `json:"kind"               yaml:"kind"`
+	Target   string `json:"target,omitempty"   yaml:"target,omitempty"`   // Header name or JSONPath, for those kinds
+	Operator string `json:"op"                 yaml:"op"`                 // One of the Op constants
+	Expected string `json:"expected,omitempty" yaml:"expected,omitempty"` // Value compared with, may hold {{variables}}
+	Disabled bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"` // Kept with the request but not checked
+}
+
+// 'Result' is the outcome of checking an assertion against a response.
+type Result struct {
+	Assertion Assertion
+	Passed    bool
+	Actual    string // Value found in the response
+	Error     string // Why the value could not be found or compared, the assertion then fails
+}
+
+// Function 'Parse' reads an assertion written as a line:
+//
+//	status == 200
+//	header Content-Type contains json
+//	$.data[0].id == 42           (json $.data[0].id == 42, or jq: .data[0].id == 42)
+//	body contains "welcome"
+//	time < 500ms                 (or 1.5s)
+//
+// The expected value is the rest of the line, quote it to keep spaces around it.
+func Parse(line string) (Assertion, error) {
+	line = strings.TrimSpace(line)
+	var a Assertion
+	var rest string
+	switch {
+	case line == "":
+		return a, fmt.Errorf("empty assertion")
+	case strings.HasPrefix(line, "$") || strings.HasPrefix(line, "."):
+		a.Kind, rest = KindJSON, line
+	default:
+		kind, after, _ := strings.Cut(line, " ")
+		a.Kind, rest = strings.ToLower(kind), strings.TrimSpace(after)
+	}
+	valid, ok := operators[a.Kind]
+	if !ok {
+		return a, fmt.Errorf("unknown check %q, use status, header, json, body or time", a.Kind)
+	}
+
+	target, operator, expected := splitOperator(rest)
+	if operator == "" {
+		return a, fmt.Errorf("missing operator, use one of %s", strings.Join(valid, " "))
+	}
+	switch a.Kind {
+	case KindHeader, KindJSON:
+		if target == "" && a.Kind == KindHeader {
+			return a, fmt.Errorf("missing header name before %s", operator)
+		}
+		if target == "" {
+			return a, fmt.Errorf("missing path before %s", operator)
+		}
+		if a.Kind == KindJSON {
+			if _, err := jsonpath.Compile(target); err != nil {
+				return a, err
+			}
+		}
+	default:
+		if target != "" {
+			return a, fmt.Errorf("unexpected %q before %s", target, operator)
+		}
+	}
+	a.Target, a.Operator = target, operator
+	if !contains(valid, operator) {
+		return a, fmt.Errorf("%s cannot be used with %s, use one of %s", operator, a.Kind, strings.Join(valid, " "))
+	}
+
+	if unquoted, err := strconv.Unquote(expected); err == nil && strings.HasPrefix(expected, `"`) {
+		expected = unquoted
+	}
+	a.Expected = expected
+	switch {
+	case operator == OpExists || operator == OpNotExists:
+		if expected != "" {
+			return a, fmt.Errorf("%s takes no value", operator)
+		}
+	case expected == "" && a.Kind != KindBody && a.Kind != KindHeader && a.Kind != KindJSON:
+		return a, fmt.Errorf("missing value after %s", operator)
+	case a.Kind == KindTime:
+		if _, err := parseMillis(expected); err != nil {
+			return a, err
+		}
+	case a.Kind == KindStatus:
+		if _, err := strconv.Atoi(expected); err != nil {
+			return a, fmt.Errorf("status %q is not a number", expected)
+		}
+	case operator == OpMatches:
+		if _, err := regexp.Compile(expected); err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+// Function 'String' writes the assertion as a line Parse reads back.
+func (a Assertion) String() string {
+	parts := []string{a.Kind}
+	switch {
+	case a.Kind == KindJSON && (strings.HasPrefix(a.Target, "$") || strings.HasPrefix(a.Target, ".")):
+		parts = []string{a.Target}
+	case a.Target != "":
+		parts = append(parts, a.Target)
+	}
+	parts = append(parts, a.Operator)
+	if a.Operator != OpExists && a.Operator != OpNotExists {
+		expected := a.Expected
+		if expected == "" || strings.TrimSpace(expected) != expected || strings.HasPrefix(expected, `"`) {
+			expected = strconv.Quote(expected)
+		}
+		parts = append(parts, expected)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Function 'Evaluate' checks every enabled assertion against a response, in order.
+func Evaluate(assertions []Assertion, response httpclient.HttpResponseDetails) []Result {
+	var results []Result
+	for _, a := range assertions {
+		if !a.Disabled {
+			results = append(results, a.Evaluate(response))
+		}
+	}
+	return results
+}
+
+// Function 'Passed' counts the results that passed.
+func Passed(results []Result) int {
+	passed := 0
+	for _, result := range results {
+		if result.Passed {
+			passed++
+		}
+	}
+	return passed
+}
+
+// Function 'Evaluate' checks the assertion against a response.
+func (a Assertion) Evaluate(response httpclient.HttpResponseDetails) Result {
+	result := Result{Assertion: a}
+	if response.Error != nil {
+		result.Error = "no response"
+		return result
+	}
+
+	found := true
+	switch a.Kind {
+	case KindStatus:
+		result.Actual = strconv.Itoa(response.StatusCode)
+	case KindHeader:
+		values := response.HeaderValues(a.Target)
+		found = len(values) > 0
+		result.Actual = strings.Join(values, ", ")
+	case KindJSON:
+		if response.JsonData == nil {
+			result.Error = "the body is not JSON"
+			return result
+		}
+		expression, err := jsonpath.Compile(a.Target)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		values := expression.Evaluate(response.JsonData)
+		found = len(values) > 0
+		if expression.Definite() && found {
+			result.Actual = valueText(values[0])
+		} else if found {
+			result.Actual = valueText(values)
+		}
+	case KindBody:
+		result.Actual = string(response.Body)
+	case KindTime:
+		result.Actual = strconv.FormatInt(response.Timing.Total.Milliseconds(), 10)
+	default:
+		result.Error = fmt.Sprintf("unknown check %q", a.Kind)
+		return result
+	}
+
+	switch {
+	case a.Operator == OpExists:
+		result.Passed = found
+	case a.Operator == OpNotExists:
+		result.Passed = !found
+	case !found:
+		result.Error = "not found"
+	default:
+		result.Passed, result.Error = compare(a.Operator, result.Actual, a.Expected, a.Kind == KindTime)
+	}
+	return result
+}
+
+// Function 'compare' applies an operator to the actual and expected values. Equality compares
+// numbers by value when both sides are numbers, ordering requires numbers. Times are expected in
+// milliseconds or with a unit.
+func compare(operator, actual, expected string, isTime bool) (bool, string) {
+	switch operator {
+	case OpContains:
+		return strings.Contains(actual, expected), ""
+	case OpNotContains:
+		return !strings.Contains(actual, expected), ""
+	case OpMatches:
+		re, err := regexp.Compile(expected)
+		if err != nil {
+			return false, err.Error()
+		}
+		return re.MatchString(actual), ""
+	}
+
+	left, leftErr := strconv.ParseFloat(actual, 64)
+	right, rightErr := strconv.ParseFloat(expected, 64)
+	if isTime {
+		right, rightErr = parseMillis(expected)
+	}
+	numbers := leftErr == nil && rightErr == nil
+	switch operator {
+	case OpEquals:
+		return numbers && left == right || !numbers && actual == expected, ""
+	case OpNotEquals:
+		return numbers && left != right || !numbers && actual != expected, ""
+	}
+	if !numbers {
+		return false, fmt.Sprintf("%s needs numbers", operator)
+	}
+	switch operator {
+	case OpLess:
+		return left < right, ""
+	case OpLessOrEqual:
+		return left <= right, ""
+	case OpGreater:
+		return left > right, ""
+	case OpGreaterOrEqual:
+		return left >= right, ""
+	}
+	return false, fmt.Sprintf("unknown operator %q", operator)
+}
+
+// Function 'splitOperator' splits text into what comes before the first operator, the operator
+// and what comes after it. Operators inside brackets or quotes, as in the filters of a JSONPath,
+// are part of the target.
+func splitOperator(text string) (string, string, string) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '\'' || c == '"':
+			quote = c
+			continue
+		case c == '[' || c == '(':
+			depth++
+			continue
+		case c == ']' || c == ')':
+			depth--
+			continue
+		case depth > 0 || i > 0 && text[i-1] != ' ':
+			continue
+		}
+		for _, operator := range allOperators {
+			if !strings.HasPrefix(text[i:], operator) {
+				continue
+			}
+			if rest := text[i+len(operator):]; rest == "" || rest[0] == ' ' {
+				return strings.TrimSpace(text[:i]), operator, strings.TrimSpace(rest)
+			}
+		}
+	}
+	return strings.TrimSpace(text), "", ""
+}
+
+// 'allOperators' are tried in order by splitOperator, so longer operators win over their prefixes.
+var allOperators = []string{
+	OpEquals, OpNotEquals, OpLessOrEqual, OpGreaterOrEqual, OpLess, OpGreater,
+	OpNotContains, OpContains, OpMatches, OpNotExists, OpExists,
+}
+
+// Function 'parseMillis' reads a time in milliseconds, or with a unit such as 500ms or 1.5s.
+func parseMillis(text string) (float64, error) {
+	if ms, err := strconv.ParseFloat(text, 64); err == nil {
+		return ms, nil
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("time %q is not a number of milliseconds or a duration such as 1.5s", text)
+	}
+	return float64(d) / float64(time.Millisecond), nil
+}
+
+// Function 'valueText' shows a JSON value: strings as they are, anything else as JSON.
+func valueText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// Function 'contains' reports whether a list holds a string.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
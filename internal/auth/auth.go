@@ -0,0 +1,234 @@
+package auth // Package 'auth' applies authentication schemes to outgoing requests
+
+import (
+	"context" // For cancelling Prepare
+	"fmt"     // For unknown scheme errors
+	"strings" // For comparing header names
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// Names of the supported schemes, as stored in the Type field of Auth.
+const (
+	TypeNone   = ""
+	TypeBearer = "bearer"
+	TypeBasic  = "basic"
+	TypeAPIKey = "apikey"
+	TypeDigest = "digest"
+	TypeAWSV4  = "awsv4"
+	TypeOAuth2 = "oauth2"
+)
+
+// 'Auth' holds the credentials of a request. Type picks the scheme, and only the fields that
+// scheme uses are set.
+type Auth struct {
+	Type string `json:"type" yaml:"type"`
+
+	Token    string `json:"token,omitempty"    yaml:"token,omitempty"`    // Bearer
+	Username string `json:"username,omitempty" yaml:"username,omitempty"` // Basic, Digest
+	Password string `json:"password,omitempty" yaml:"password,omitempty"` // Basic, Digest
+
+	Key   string `json:"key,omitempty"   yaml:"key,omitempty"`   // API key name
+	Value string `json:"value,omitempty" yaml:"value,omitempty"` // API key value
+	In    string `json:"in,omitempty"    yaml:"in,omitempty"`    // API key location, "header" (default) or "query"
+
+	AccessKey    string `json:"accessKey,omitempty"    yaml:"accessKey,omitempty"`    // AWS
+	SecretKey    string `json:"secretKey,omitempty"    yaml:"secretKey,omitempty"`    // AWS
+	SessionToken string `json:"sessionToken,omitempty" yaml:"sessionToken,omitempty"` // AWS temporary credentials
+	Region       string `json:"region,omitempty"       yaml:"region,omitempty"`       // AWS
+	Service      string `json:"service,omitempty"      yaml:"service,omitempty"`      // AWS
+
+	Grant        string `json:"grant,omitempty"        yaml:"grant,omitempty"`        // OAuth2 grant type
+	TokenURL     string `json:"tokenUrl,omitempty"     yaml:"tokenUrl,omitempty"`     // OAuth2
+	AuthURL      string `json:"authUrl,omitempty"      yaml:"authUrl,omitempty"`      // OAuth2 authorization code grant
+	ClientID     string `json:"clientId,omitempty"     yaml:"clientId,omitempty"`     // OAuth2
+	ClientSecret string `json:"clientSecret,omitempty" yaml:"clientSecret,omitempty"` // OAuth2
+	Scope        string `json:"scope,omitempty"        yaml:"scope,omitempty"`        // OAuth2
+	RefreshToken string `json:"refreshToken,omitempty" yaml:"refreshToken,omitempty"` // OAuth2 refresh token grant
+	RedirectPort string `json:"redirectPort,omitempty" yaml:"redirectPort,omitempty"` // OAuth2 authorization code grant
+}
+
+// 'Field' describes one input of a scheme, so the UI can build its form.
+type Field struct {
+	Label   string              // Label shown in the form
+	Secret  bool                // Masked in the UI and left out of the history
+	Options []string            // When set, the field is a choice between these values
+	Value   func(*Auth) *string // The Auth field the input edits
+}
+
+// 'Scheme' is an authentication scheme. Apply runs at send time, once the request is complete.
+type Scheme interface {
+	Label() string                                                  // Name shown in the scheme dropdown
+	Fields() []Field                                                // Inputs of the scheme, in display order
+	Apply(auth *Auth, details *httpclient.HttpRequestDetails) error // Adds the credentials to the request
+}
+
+// 'Preparer' is implemented by schemes that need to fetch something, such as a token, before Apply
+// can run. Prepare may take long and is run off the UI goroutine.
+type Preparer interface {
+	Prepared(auth *Auth) bool // Reports whether Apply can run right away
+	Prepare(ctx context.Context, auth *Auth, settings httpclient.ClientSettings, authorize func(authURL string)) error
+}
+
+// Types lists the scheme names in the order they are offered.
+var Types = []string{TypeNone, TypeBearer, TypeBasic, TypeAPIKey, TypeDigest, TypeAWSV4, TypeOAuth2}
+
+// schemes maps every entry of Types to its implementation.
+var schemes = map[string]Scheme{
+	TypeNone:   none{},
+	TypeBearer: bearer{},
+	TypeBasic:  basic{},
+	TypeAPIKey: apiKey{},
+	TypeDigest: digest{},
+	TypeAWSV4:  awsV4{},
+	TypeOAuth2: oauth2Scheme{},
+}
+
+// Function 'SchemeFor' returns the implementation of a scheme name.
+func SchemeFor(name string) (Scheme, error) {
+	scheme, ok := schemes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown auth scheme %q", name)
+	}
+	return scheme, nil
+}
+
+// Function 'Apply' adds the credentials to the request. A nil Auth leaves the request alone.
+func (a *Auth) Apply(details *httpclient.HttpRequestDetails) error {
+	if a == nil {
+		return nil
+	}
+	scheme, err := SchemeFor(a.Type)
+	if err != nil {
+		return err
+	}
+	return scheme.Apply(a, details)
+}
+
+// Function 'Prepared' reports whether Apply can run without calling Prepare first.
+func (a *Auth) Prepared() bool {
+	if a == nil {
+		return true
+	}
+	scheme, err := SchemeFor(a.Type)
+	if err != nil {
+		return true // Apply reports the unknown scheme
+	}
+	preparer, ok := scheme.(Preparer)
+	return !ok || preparer.Prepared(a)
+}
+
+// Function 'Prepare' fetches what the scheme needs before Apply can run. The settings are used for
+// any request it makes, authorize is called with a URL the user has to open in a browser.
+func (a *Auth) Prepare(ctx context.Context, settings httpclient.ClientSettings, authorize func(authURL string)) error {
+	if a == nil {
+		return nil
+	}
+	scheme, err := SchemeFor(a.Type)
+	if err != nil {
+		return err
+	}
+	if preparer, ok := scheme.(Preparer); ok {
+		return preparer.Prepare(ctx, a, settings, authorize)
+	}
+	return nil
+}
+
+// Function 'Clone' returns a copy of the credentials.
+func (a *Auth) Clone() *Auth {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	return &clone
+}
+
+// Function 'Map' returns a copy with fn applied to every text field, e.g. to fill in variables.
+func (a *Auth) Map(fn func(string) string) *Auth {
+	if a == nil {
+		return nil
+	}
+	mapped := a.Clone()
+	for _, field := range allFields(mapped) {
+		*field = fn(*field)
+	}
+	return mapped
+}
+
+// Function 'Redacted' returns a copy without the secret fields of its scheme, for storing where
+// credentials do not belong such as the history.
+func (a *Auth) Redacted() *Auth {
+	if a == nil {
+		return nil
+	}
+	redacted := a.Clone()
+	scheme, err := SchemeFor(a.Type)
+	if err != nil {
+		return &Auth{Type: a.Type}
+	}
+	for _, field := range scheme.Fields() {
+		if field.Secret {
+			*field.Value(redacted) = ""
+		}
+	}
+	return redacted
+}
+
+// secretHeaders are the headers that carry credentials whatever the scheme of the request.
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Function 'SecretHeader' reports whether a header carries credentials, such as Authorization or
+// Cookie, so its value is masked in the UI and left out of the history.
+func SecretHeader(name string) bool {
+	for _, secret := range secretHeaders {
+		if strings.EqualFold(strings.TrimSpace(name), secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function 'SecretHeader' reports whether a header of a request sent with these credentials carries
+// a secret: one of the headers SecretHeader knows, the header of an API key or the session token of
+// AWS temporary credentials.
+func (a *Auth) SecretHeader(name string) bool {
+	name = strings.TrimSpace(name)
+	switch {
+	case SecretHeader(name):
+		return true
+	case a == nil:
+		return false
+	case a.Type == TypeAPIKey && a.In != "query":
+		return strings.EqualFold(name, a.Key)
+	case a.Type == TypeAWSV4:
+		return strings.EqualFold(name, "X-Amz-Security-Token")
+	}
+	return false
+}
+
+// Function 'Upgrade' turns the bare key/value token saved by older versions, which has no Type,
+// into the API key header it used to be sent as. A value without a key was an Authorization header.
+func (a *Auth) Upgrade() *Auth {
+	if a == nil || a.Type != TypeNone || a.Value == "" {
+		return a
+	}
+	upgraded := &Auth{Type: TypeAPIKey, Key: a.Key, Value: a.Value, In: "header"}
+	if upgraded.Key == "" {
+		upgraded.Key = "Authorization"
+	}
+	return upgraded
+}
+
+// Function 'IsEmpty' reports whether no scheme is selected.
+func (a *Auth) IsEmpty() bool {
+	return a == nil || a.Type == TypeNone
+}
+
+// Function 'allFields' lists pointers to every text field except Type.
+func allFields(a *Auth) []*string {
+	return []*string{
+		&a.Token, &a.Username, &a.Password, &a.Key, &a.Value, &a.In,
+		&a.AccessKey, &a.SecretKey, &a.SessionToken, &a.Region, &a.Service,
+		&a.Grant, &a.TokenURL, &a.AuthURL, &a.ClientID, &a.ClientSecret, &a.Scope, &a.RefreshToken, &a.RedirectPort,
+	}
+}
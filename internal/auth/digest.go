@@ -0,0 +1,118 @@
+package auth // Package 'auth' applies authentication schemes to outgoing requests
+
+import (
+	"crypto/md5"    // For the MD5 digest algorithms
+	"crypto/rand"   // For the client nonce
+	"crypto/sha256" // For the SHA-256 digest algorithms
+	"encoding/hex"  // For hashing to hex strings
+	"fmt"           // For building the Authorization header
+	"hash"          // For picking the hash function
+	"strings"       // For parsing the challenge
+)
+
+// Function 'digestAuthorization' computes the Authorization header answering a Digest challenge.
+// params is the challenge without its leading "Digest" (realm="...", nonce="...", qop="auth", ...).
+func digestAuthorization(username, password, method, uri, params string) (string, error) {
+	challenge := parseChallenge(params)
+	realm, nonce := challenge["realm"], challenge["nonce"]
+	if nonce == "" {
+		return "", fmt.Errorf("digest challenge without a nonce")
+	}
+
+	algorithm := challenge["algorithm"]
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("unsupported digest algorithm %q", algorithm)
+	}
+	h := func(s string) string {
+		sum := newHash()
+		sum.Write([]byte(s))
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+
+	cnonce := make([]byte, 8)
+	if _, err := rand.Read(cnonce); err != nil {
+		return "", err
+	}
+	clientNonce := hex.EncodeToString(cnonce)
+	const nonceCount = "00000001" // Every challenge is answered once
+
+	ha1 := h(username + ":" + realm + ":" + password)
+	if strings.HasSuffix(strings.ToUpper(algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + nonce + ":" + clientNonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	// Prefer qop=auth; without qop the legacy RFC 2069 form is used
+	qop := ""
+	for _, option := range strings.Split(challenge["qop"], ",") {
+		if strings.TrimSpace(option) == "auth" {
+			qop = "auth"
+		}
+	}
+	var response string
+	if qop == "" {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + nonceCount + ":" + clientNonce + ":" + qop + ":" + ha2)
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		quote(username), quote(realm), quote(nonce), quote(uri), response)
+	if algorithm != "" {
+		fmt.Fprintf(b, ", algorithm=%s", algorithm)
+	}
+	if qop != "" {
+		fmt.Fprintf(b, `, qop=%s, nc=%s, cnonce="%s"`, qop, nonceCount, clientNonce)
+	}
+	if opaque, ok := challenge["opaque"]; ok {
+		fmt.Fprintf(b, `, opaque="%s"`, quote(opaque))
+	}
+	return b.String(), nil
+}
+
+// Function 'parseChallenge' splits the parameters of a challenge into a map. Names are lower cased
+// and quoted values unquoted, commas inside quotes are kept.
+func parseChallenge(params string) map[string]string {
+	values := make(map[string]string)
+	for len(params) > 0 {
+		params = strings.TrimLeft(params, " ,")
+		name, rest, ok := strings.Cut(params, "=")
+		if !ok {
+			break
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		rest = strings.TrimLeft(rest, " ")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted string, backslash escapes the next character
+			b := &strings.Builder{}
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+				b.WriteByte(rest[i])
+			}
+			value = b.String()
+			params = rest[min(i+1, len(rest)):]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// Function 'quote' escapes a value for use inside a quoted header parameter.
+func quote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,67 @@
+package auth // Package 'auth' applies authentication schemes to outgoing requests
+
+import (
+	"context" // For cancelling a token request
+	"errors"  // For a missing token
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+	"github.com/SiirRandall/go-restful/internal/oauth2"                // OAuth 2.0 token module
+)
+
+// 'oauth2Scheme' sends an OAuth 2.0 access token, fetched by Prepare and cached until it expires.
+type oauth2Scheme struct{}
+
+func (oauth2Scheme) Label() string { return "OAuth 2.0" }
+
+func (oauth2Scheme) Fields() []Field {
+	return []Field{
+		{Label: "Grant", Options: oauth2.Grants, Value: func(a *Auth) *string { return &a.Grant }},
+		{Label: "Token URL", Value: func(a *Auth) *string { return &a.TokenURL }},
+		{Label: "Auth URL", Value: func(a *Auth) *string { return &a.AuthURL }},
+		{Label: "Client ID", Value: func(a *Auth) *string { return &a.ClientID }},
+		{Label: "Client secret", Secret: true, Value: func(a *Auth) *string { return &a.ClientSecret }},
+		{Label: "Scope", Value: func(a *Auth) *string { return &a.Scope }},
+		{Label: "Username", Value: func(a *Auth) *string { return &a.Username }},
+		{Label: "Password", Secret: true, Value: func(a *Auth) *string { return &a.Password }},
+		{Label: "Refresh token", Secret: true, Value: func(a *Auth) *string { return &a.RefreshToken }},
+		{Label: "Redirect port", Value: func(a *Auth) *string { return &a.RedirectPort }},
+	}
+}
+
+// Function 'Apply' sets the Authorization header from the cached token.
+func (oauth2Scheme) Apply(a *Auth, details *httpclient.HttpRequestDetails) error {
+	token, ok := oauth2.Cached(oauthConfig(a))
+	if !ok || !token.Valid() {
+		return errors.New("no valid OAuth2 token yet, one is fetched when the request is sent")
+	}
+	setHeader(details, "Authorization", token.Authorization())
+	return nil
+}
+
+// Function 'Prepared' reports whether a valid token is cached.
+func (oauth2Scheme) Prepared(a *Auth) bool {
+	token, ok := oauth2.Cached(oauthConfig(a))
+	return ok && token.Valid()
+}
+
+// Function 'Prepare' fetches a new token or refreshes the cached one.
+func (oauth2Scheme) Prepare(ctx context.Context, a *Auth, settings httpclient.ClientSettings, authorize func(authURL string)) error {
+	_, err := oauth2.Fetch(ctx, oauthConfig(a), settings, authorize)
+	return err
+}
+
+// Function 'oauthConfig' picks the OAuth2 fields out of the credentials.
+func oauthConfig(a *Auth) oauth2.Config {
+	return oauth2.Config{
+		Grant:        a.Grant,
+		TokenURL:     a.TokenURL,
+		AuthURL:      a.AuthURL,
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		Scope:        a.Scope,
+		Username:     a.Username,
+		Password:     a.Password,
+		RefreshToken: a.RefreshToken,
+		RedirectPort: a.RedirectPort,
+	}
+}
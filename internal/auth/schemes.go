@@ -0,0 +1,169 @@
+package auth // Package 'auth' applies authentication schemes to outgoing requests
+
+import (
+	"encoding/base64" // For Basic credentials
+	"errors"          // For incomplete credentials
+	"net/url"         // For API keys sent in the query string
+	"strings"         // For case-insensitive header handling
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// 'none' sends the request without credentials.
+type none struct{}
+
+func (none) Label() string   { return "No auth" }
+func (none) Fields() []Field { return nil }
+
+// Function 'Apply' leaves the request alone.
+func (none) Apply(*Auth, *httpclient.HttpRequestDetails) error { return nil }
+
+// 'bearer' sends a token in an "Authorization: Bearer" header.
+type bearer struct{}
+
+func (bearer) Label() string { return "Bearer token" }
+
+func (bearer) Fields() []Field {
+	return []Field{
+		{Label: "Token", Secret: true, Value: func(a *Auth) *string { return &a.Token }},
+	}
+}
+
+// Function 'Apply' sets the Authorization header.
+func (bearer) Apply(a *Auth, details *httpclient.HttpRequestDetails) error {
+	if a.Token == "" {
+		return nil
+	}
+	setHeader(details, "Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// 'basic' sends a username and password in an "Authorization: Basic" header.
+type basic struct{}
+
+func (basic) Label() string { return "Basic auth" }
+
+func (basic) Fields() []Field {
+	return []Field{
+		{Label: "Username", Value: func(a *Auth) *string { return &a.Username }},
+		{Label: "Password", Secret: true, Value: func(a *Auth) *string { return &a.Password }},
+	}
+}
+
+// Function 'Apply' sets the Authorization header.
+func (basic) Apply(a *Auth, details *httpclient.HttpRequestDetails) error {
+	if a.Username == "" && a.Password == "" {
+		return nil
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+	setHeader(details, "Authorization", "Basic "+credentials)
+	return nil
+}
+
+// 'apiKey' sends a named key either as a header or as a query parameter.
+type apiKey struct{}
+
+func (apiKey) Label() string { return "API key" }
+
+func (apiKey) Fields() []Field {
+	return []Field{
+		{Label: "Key", Value: func(a *Auth) *string { return &a.Key }},
+		{Label: "Value", Secret: true, Value: func(a *Auth) *string { return &a.Value }},
+		{Label: "Add to", Options: []string{"header", "query"}, Value: func(a *Auth) *string { return &a.In }},
+	}
+}
+
+// Function 'Apply' adds the key to the headers, or appends it to the query of the URL.
+func (apiKey) Apply(a *Auth, details *httpclient.HttpRequestDetails) error {
+	if a.Key == "" {
+		return nil
+	}
+	if a.In != "query" {
+		setHeader(details, a.Key, a.Value)
+		return nil
+	}
+
+	u, err := url.Parse(details.URL)
+	if err != nil {
+		return err
+	}
+	// The param is appended to the query as it is, so the params of the request keep their order,
+	// repeated keys and encoding
+	param := url.QueryEscape(a.Key) + "=" + url.QueryEscape(a.Value)
+	if u.RawQuery != "" {
+		param = "&" + param
+	}
+	u.RawQuery += param
+	details.URL = u.String()
+	return nil
+}
+
+// 'digest' answers the server's Digest challenge (RFC 7616). The first request goes out without
+// credentials, the response to the 401 it gets carries them.
+type digest struct{}
+
+func (digest) Label() string { return "Digest auth" }
+
+func (digest) Fields() []Field {
+	return []Field{
+		{Label: "Username", Value: func(a *Auth) *string { return &a.Username }},
+		{Label: "Password", Secret: true, Value: func(a *Auth) *string { return &a.Password }},
+	}
+}
+
+// Function 'Apply' registers the challenge handler.
+func (digest) Apply(a *Auth, details *httpclient.HttpRequestDetails) error {
+	if a.Username == "" {
+		return nil
+	}
+	username, password := a.Username, a.Password
+	details.Challenge = func(method, uri string, challenges []string) (string, error) {
+		for _, challenge := range challenges {
+			if scheme, params, _ := strings.Cut(challenge, " "); strings.EqualFold(scheme, "Digest") {
+				return digestAuthorization(username, password, method, uri, params)
+			}
+		}
+		return "", errors.New("server did not offer Digest authentication")
+	}
+	details.Digest = &httpclient.Credentials{Username: username, Password: password}
+	return nil
+}
+
+// 'awsV4' signs the request with AWS Signature Version 4.
+type awsV4 struct{}
+
+func (awsV4) Label() string { return "AWS Signature v4" }
+
+func (awsV4) Fields() []Field {
+	return []Field{
+		{Label: "Access key", Value: func(a *Auth) *string { return &a.AccessKey }},
+		{Label: "Secret key", Secret: true, Value: func(a *Auth) *string { return &a.SecretKey }},
+		{Label: "Session token", Secret: true, Value: func(a *Auth) *string { return &a.SessionToken }},
+		{Label: "Region", Value: func(a *Auth) *string { return &a.Region }},
+		{Label: "Service", Value: func(a *Auth) *string { return &a.Service }},
+	}
+}
+
+// Function 'Apply' signs the request as it is now, so it has to run after everything else changed it.
+func (awsV4) Apply(a *Auth, details *httpclient.HttpRequestDetails) error {
+	if a.AccessKey == "" || a.SecretKey == "" {
+		return errors.New("AWS signing needs an access key and a secret key")
+	}
+	if a.Region == "" || a.Service == "" {
+		return errors.New("AWS signing needs a region and a service")
+	}
+	return signV4(a, details, now().UTC())
+}
+
+// Function 'setHeader' replaces a header, whatever the case of its current name.
+func setHeader(details *httpclient.HttpRequestDetails, name, value string) {
+	if details.Headers == nil {
+		details.Headers = make(map[string]string)
+	}
+	for key := range details.Headers {
+		if strings.EqualFold(key, name) {
+			delete(details.Headers, key)
+		}
+	}
+	details.Headers[name] = value
+}
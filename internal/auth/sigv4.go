@@ -0,0 +1,120 @@
+package auth // Package 'auth' applies authentication schemes to outgoing requests
+
+import (
+	"crypto/hmac"   // For deriving the signing key and the signature
+	"crypto/sha256" // For hashing the canonical request and the payload
+	"encoding/hex"  // For hex encoded hashes
+	"fmt"           // For building the Authorization header
+	"net/url"       // For the canonical URI and query string
+	"sort"          // For the canonical ordering of headers and query parameters
+	"strings"       // For string manipulations
+	"time"          // For the request date
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// now returns the signing time, a variable so the signature can be reproduced.
+var now = time.Now
+
+// Function 'signV4' adds the X-Amz-Date, X-Amz-Content-Sha256 and Authorization headers (and
+// X-Amz-Security-Token for temporary credentials) of an AWS Signature Version 4 to the request.
+func signV4(a *Auth, details *httpclient.HttpRequestDetails, t time.Time) error {
+	if err := details.EncodeBody(); err != nil { // The payload hash needs the final body
+		return err
+	}
+	u, err := url.Parse(details.URL)
+	if err != nil {
+		return err
+	}
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(details.RequestBody)
+
+	setHeader(details, "X-Amz-Date", amzDate)
+	setHeader(details, "X-Amz-Content-Sha256", payloadHash)
+	if a.SessionToken != "" {
+		setHeader(details, "X-Amz-Security-Token", a.SessionToken)
+	}
+
+	// Sign the host, the content type and every x-amz-* header
+	signed := map[string]string{"host": u.Host}
+	for key, value := range details.Headers {
+		name := strings.ToLower(key)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			signed[name] = strings.Join(strings.Fields(value), " ")
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(canonicalHeaders, "%s:%s\n", name, signed[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		details.Method,
+		path,
+		canonicalQuery(u.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, a.Region, a.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.SecretKey), date)
+	key = hmacSHA256(key, a.Region)
+	key = hmacSHA256(key, a.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	setHeader(details, "Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.AccessKey, scope, signedHeaders, signature,
+	))
+	return nil
+}
+
+// Function 'canonicalQuery' sorts the query parameters and encodes them the way AWS expects (RFC 3986).
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// Function 'awsEscape' percent-encodes everything except unreserved characters.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// Function 'sha256Hex' returns the hex encoded SHA-256 of s.
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Function 'hmacSHA256' returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
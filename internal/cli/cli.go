@@ -0,0 +1,235 @@
+package cli // Package 'cli' runs go-restful subcommands without the terminal UI, for scripts and CI
+
+import (
+	"context"   // For cancelling a run on Ctrl+C
+	"errors"    // For telling help requests apart from usage errors
+	"flag"      // For parsing the options of a subcommand
+	"fmt"       // For formatted output
+	"io"        // For the output streams
+	"os"        // For the standard streams and report files
+	"os/signal" // For Ctrl+C
+	"strings"   // For variables given as name=value
+
+	"github.com/SiirRandall/go-restful/internal/collection"  // Saved requests module
+	"github.com/SiirRandall/go-restful/internal/environment" // Variables substituted into the requests
+	"github.com/SiirRandall/go-restful/internal/oauth2"      // For opening OAuth2 logins in a browser
+	"github.com/SiirRandall/go-restful/internal/runner"      // Sends the requests and checks them
+	"github.com/SiirRandall/go-restful/internal/settings"    // Application wide client settings
+)
+
+// Exit codes of the subcommands.
+const (
+	ExitOK     = 0 // Every request passed
+	ExitFailed = 1 // A request failed or a check did not pass
+	ExitUsage  = 2 // The command line was wrong or the collection could not be loaded
+)
+
+// usage is shown by 'help' and after a usage error.
+const usage = `Usage:
+  go-restful                         start the terminal UI
+  go-restful run <collection> [options]
+                                     send the requests of a collection and check their responses
+  go-restful help                    show this help
+
+<collection> is the name of a saved collection or the path of a collection file.
+
+Options of run:
+`
+
+// 'variables' collects the --var name=value options.
+type variables []string
+
+// Function 'String' shows the variables, as flag.Value requires.
+func (v *variables) String() string { return strings.Join(*v, ", ") }
+
+// Function 'Set' adds a variable given on the command line.
+func (v *variables) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("%q is not name=value", value)
+	}
+	*v = append(*v, value)
+	return nil
+}
+
+// Function 'Main' runs the subcommand named by args[0] and returns the exit code of the program.
+func Main(args []string) int {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+// Function 'run' is Main writing to the given streams.
+func run(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "run":
+		return runCollection(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		runFlags(new(runOptions), stdout).PrintDefaults()
+		return ExitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		runFlags(new(runOptions), stderr).PrintDefaults()
+		return ExitUsage
+	}
+}
+
+// 'runOptions' holds the options of the run subcommand.
+type runOptions struct {
+	environment string
+	variables   variables
+	folder      string
+	request     string
+	bail        bool
+	junit       string
+	json        string
+	quiet       bool
+}
+
+// Function 'runFlags' declares the options of the run subcommand.
+func runFlags(options *runOptions, output io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(output)
+	flags.StringVar(&options.environment, "env", "", "name of a saved environment or path of an environment file")
+	flags.Var(&options.variables, "var", "set a variable as name=value, over the environment (repeatable)")
+	flags.StringVar(&options.folder, "folder", "", "only run the requests below this folder path, e.g. users/admin")
+	flags.StringVar(&options.request, "request", "", "only run the requests with this name")
+	flags.BoolVar(&options.bail, "bail", false, "stop after the first failed request")
+	flags.StringVar(&options.junit, "junit", "", "write a JUnit XML report to this file, - for standard output")
+	flags.StringVar(&options.json, "json", "", "write a JSON report to this file, - for standard output")
+	flags.BoolVar(&options.quiet, "quiet", false, "only print the summary and failed requests")
+	return flags
+}
+
+// Function 'parseInterspersed' parses the options of a flag set, which may come before or after
+// the positional arguments, and returns the positional arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Function 'runCollection' is the run subcommand: it sends the requests of a collection, prints a
+// line per request and a summary, and writes the reports asked for.
+func runCollection(args []string, stdout, stderr io.Writer) int {
+	options := &runOptions{}
+	flags := runFlags(options, stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	positional, err := parseInterspersed(flags, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		fmt.Fprintf(stderr, "run takes one collection, got %d\n\n", len(positional))
+		flags.Usage()
+		return ExitUsage
+	}
+
+	c, err := collection.Find(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading collection: %v\n", err)
+		return ExitUsage
+	}
+	var env *environment.Environment
+	if options.environment != "" {
+		if env, err = environment.Find(options.environment); err != nil {
+			fmt.Fprintf(stderr, "Error loading environment: %v\n", err)
+			return ExitUsage
+		}
+	}
+	if len(options.variables) > 0 && env == nil {
+		env = &environment.Environment{}
+	}
+	for _, variable := range options.variables {
+		name, value, _ := strings.Cut(variable, "=")
+		env.Set(strings.TrimSpace(name), value)
+	}
+	defaults, err := settings.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading settings, using the defaults: %v\n", err)
+	}
+
+	// A report written to standard output takes it over the progress lines
+	progress := stdout
+	if options.json == "-" || options.junit == "-" {
+		progress = stderr
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := runner.Run(ctx, c, runner.Options{
+		Environment: env,
+		Settings:    defaults.Client,
+		Folder:      options.folder,
+		Request:     options.request,
+		Bail:        options.bail,
+		Authorize: func(authURL string) {
+			fmt.Fprintf(stderr, "Open this URL to authorize: %s\n", authURL)
+			if err := oauth2.OpenBrowser(authURL); err != nil {
+				fmt.Fprintf(stderr, "Could not open a browser: %v\n", err)
+			}
+		},
+		Progress: func(result runner.Result) {
+			if !options.quiet || !result.Passed() {
+				runner.WriteResult(progress, result)
+			}
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	runner.WriteSummary(progress, report)
+	if ctx.Err() != nil {
+		fmt.Fprintln(stderr, "Interrupted, the reports hold the requests sent so far")
+	}
+
+	code := ExitOK
+	if report.Failed() > 0 || ctx.Err() != nil {
+		code = ExitFailed
+	}
+	if options.junit != "" {
+		if err := writeReport(options.junit, stdout, report, runner.WriteJUnit); err != nil {
+			fmt.Fprintf(stderr, "Error writing the JUnit report: %v\n", err)
+			code = ExitFailed
+		}
+	}
+	if options.json != "" {
+		if err := writeReport(options.json, stdout, report, runner.WriteJSON); err != nil {
+			fmt.Fprintf(stderr, "Error writing the JSON report: %v\n", err)
+			code = ExitFailed
+		}
+	}
+	return code
+}
+
+// Function 'writeReport' writes a report to a file, or to stdout when path is -.
+func writeReport(path string, stdout io.Writer, report *runner.Report, write func(io.Writer, *runner.Report) error) error {
+	if path == "-" {
+		return write(stdout, report)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,239 @@
+package collection // Package 'collection' models saved requests and the folders and collections that group them
+
+import (
+	"fmt"     // For naming duplicated requests and wrapping errors
+	"strings" // For case-insensitive header lookups
+
+	"github.com/SiirRandall/go-restful/internal/assertion"             // Checks of the response
+	"github.com/SiirRandall/go-restful/internal/auth"                  // Authentication schemes module
+	"github.com/SiirRandall/go-restful/internal/extract"               // Values saved from the response into variables
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// 'KeyValue' is a single key/value row, used for params, headers and form bodies.
+type KeyValue struct {
+	Key      string `json:"key"            yaml:"key"`
+	Value    string `json:"value"          yaml:"value"`
+	File     bool   `json:"file,omitempty"     yaml:"file,omitempty"`     // Multipart bodies only: Value is the path of a file to send
+	Disabled bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"` // Params only: kept with the request but left out of the URL
+	NoValue  bool   `json:"noValue,omitempty"  yaml:"noValue,omitempty"`  // Params only: written without '=', as the flag of ?flag
+}
+
+// Function 'IsEmpty' reports whether a row has neither a key nor a value, such as a row just added.
+func (kv KeyValue) IsEmpty() bool {
+	return kv.Key == "" && kv.Value == ""
+}
+
+// 'Body' holds the request body. Mode picks which of the other fields is sent.
+type Body struct {
+	Mode        string     `json:"mode,omitempty"        yaml:"mode,omitempty"`        // One of the httpclient body modes, empty in files written before modes existed
+	ContentType string     `json:"contentType,omitempty" yaml:"contentType,omitempty"` // Raw mode: Content-Type sent unless a header sets one
+	Raw         string     `json:"raw,omitempty"         yaml:"raw,omitempty"`         // Raw mode
+	Form        []KeyValue `json:"form,omitempty"        yaml:"form,omitempty"`        // Urlencoded and multipart modes
+	File        string     `json:"file,omitempty"        yaml:"file,omitempty"`        // Binary mode: path of the file sent as the body
+}
+
+// 'Request' is a named, saved HTTP request.
+type Request struct {
+	Name    string     `json:"name"              yaml:"name"`
+	Method  string     `json:"method"            yaml:"method"`
+	URL     string     `json:"url"               yaml:"url"`
+	Params  []KeyValue `json:"params,omitempty"  yaml:"params,omitempty"`
+	Path    []KeyValue `json:"path,omitempty"    yaml:"path,omitempty"` // Values of the :name and {name} segments of the URL
+	Headers []KeyValue `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    Body       `json:"body"              yaml:"body"`
+	Auth    *auth.Auth `json:"auth,omitempty"    yaml:"auth,omitempty"`
+	Filter  string     `json:"filter,omitempty"  yaml:"filter,omitempty"` // JSONPath or jq expression the JSON viewer shows the response through
+
+	Assertions []assertion.Assertion `json:"assertions,omitempty" yaml:"assertions,omitempty"` // Checked against the response once it arrives
+	Extract    []extract.Rule        `json:"extract,omitempty"    yaml:"extract,omitempty"`    // Values of the response saved into variables of the environment
+
+	Settings *httpclient.SettingsOverride `json:"settings,omitempty" yaml:"settings,omitempty"` // Overrides of the global client settings
+}
+
+// 'Folder' groups requests and nested folders.
+type Folder struct {
+	Name     string     `json:"name"               yaml:"name"`
+	Folders  []*Folder  `json:"folders,omitempty"  yaml:"folders,omitempty"`
+	Requests []*Request `json:"requests,omitempty" yaml:"requests,omitempty"`
+}
+
+// 'Collection' is the top level folder that is stored in its own file.
+type Collection struct {
+	Folder `yaml:",inline"`
+	Path   string `json:"-" yaml:"-"` // File the collection was loaded from or will be saved to
+}
+
+// Function 'Clone' returns a deep copy of the request.
+func (r *Request) Clone() *Request {
+	clone := *r
+	clone.Params = append([]KeyValue(nil), r.Params...)
+	clone.Path = append([]KeyValue(nil), r.Path...)
+	clone.Headers = append([]KeyValue(nil), r.Headers...)
+	clone.Body.Form = append([]KeyValue(nil), r.Body.Form...)
+	clone.Assertions = append([]assertion.Assertion(nil), r.Assertions...)
+	clone.Extract = append([]extract.Rule(nil), r.Extract...)
+	clone.Auth = r.Auth.Clone()
+	clone.Settings = r.Settings.Clone()
+	return &clone
+}
+
+// Function 'HttpRequestDetails' turns the saved request into the details needed to send it, using
+// defaults for every client setting the request does not override. The path params are filled into
+// the URL here, the body is encoded when the request is sent.
+func (r *Request) HttpRequestDetails(defaults httpclient.ClientSettings) (httpclient.HttpRequestDetails, error) {
+	requestURL, err := FillPathParams(r.URL, r.Path)
+	if err != nil {
+		return httpclient.HttpRequestDetails{}, err
+	}
+
+	headers := make(map[string]string)
+	for _, header := range r.Headers {
+		headers[header.Key] = header.Value
+	}
+
+	body := &httpclient.Body{
+		Mode:        r.Body.mode(r.Headers),
+		ContentType: r.Body.ContentType,
+		Raw:         r.Body.Raw,
+		File:        r.Body.File,
+	}
+	for _, field := range r.Body.Form {
+		body.Fields = append(body.Fields, httpclient.FormField{Key: field.Key, Value: field.Value, File: field.File})
+	}
+
+	details := httpclient.HttpRequestDetails{
+		URL:      requestURL,
+		Method:   r.Method,
+		Headers:  headers,
+		Body:     body,
+		Settings: defaults.Apply(r.Settings),
+	}
+
+	// Credentials go last, signing schemes need the final URL, headers and body
+	if err := r.Auth.Apply(&details); err != nil {
+		return httpclient.HttpRequestDetails{}, fmt.Errorf("auth: %w", err)
+	}
+	return details, nil
+}
+
+// Function 'AddRequest' appends a request to the folder.
+func (f *Folder) AddRequest(r *Request) {
+	f.Requests = append(f.Requests, r)
+}
+
+// Function 'RemoveRequest' deletes a request from the folder. It reports whether the request was found.
+func (f *Folder) RemoveRequest(r *Request) bool {
+	for i, request := range f.Requests {
+		if request == r {
+			f.Requests = append(f.Requests[:i], f.Requests[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Function 'DuplicateRequest' inserts a copy of r right after it and returns the copy.
+func (f *Folder) DuplicateRequest(r *Request) *Request {
+	clone := r.Clone()
+	clone.Name = fmt.Sprintf("%s copy", r.Name)
+	for i, request := range f.Requests {
+		if request == r {
+			f.Requests = append(f.Requests[:i+1], append([]*Request{clone}, f.Requests[i+1:]...)...)
+			return clone
+		}
+	}
+	f.Requests = append(f.Requests, clone)
+	return clone
+}
+
+// Function 'RemoveFolder' deletes a sub folder. It reports whether the folder was found.
+func (f *Folder) RemoveFolder(sub *Folder) bool {
+	for i, folder := range f.Folders {
+		if folder == sub {
+			f.Folders = append(f.Folders[:i], f.Folders[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Function 'FolderByPath' walks a slash separated path of folder names below f.
+// Missing folders are created when create is set, otherwise nil is returned.
+func (f *Folder) FolderByPath(path string, create bool) *Folder {
+	current := f
+	for _, name := range strings.Split(path, "/") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var next *Folder
+		for _, folder := range current.Folders {
+			if folder.Name == name {
+				next = folder
+				break
+			}
+		}
+		if next == nil {
+			if !create {
+				return nil
+			}
+			next = &Folder{Name: name}
+			current.Folders = append(current.Folders, next)
+		}
+		current = next
+	}
+	return current
+}
+
+// Function 'Walk' calls fn for every request below f together with the folder path leading to it.
+func (f *Folder) Walk(fn func(path []string, r *Request)) {
+	f.walk(nil, fn)
+}
+
+// Function 'walk' is the recursive part of Walk.
+func (f *Folder) walk(path []string, fn func(path []string, r *Request)) {
+	for _, request := range f.Requests {
+		fn(path, request)
+	}
+	for _, folder := range f.Folders {
+		folder.walk(append(append([]string(nil), path...), folder.Name), fn)
+	}
+}
+
+// Function 'Upgrade' brings a request written by an older version up to date: the bare token
+// becomes an API key, and the body gets a mode with multipart "@path" values turned into file fields.
+func (r *Request) Upgrade() {
+	r.Auth = r.Auth.Upgrade()
+	if r.Body.Mode != "" {
+		return
+	}
+	r.Body.Mode = r.Body.mode(r.Headers)
+	if r.Body.Mode == httpclient.BodyMultipart {
+		for i, field := range r.Body.Form {
+			if strings.HasPrefix(field.Value, "@") {
+				path, _, _ := strings.Cut(strings.TrimPrefix(field.Value, "@"), ";type=") // The type is guessed from the extension now
+				r.Body.Form[i] = KeyValue{Key: field.Key, Value: path, File: true}
+			}
+		}
+	}
+}
+
+// Function 'mode' returns the body mode, working it out for bodies saved before modes existed:
+// raw text wins over form fields, which were multipart when that was the Content-Type header.
+func (b *Body) mode(headers []KeyValue) string {
+	switch {
+	case b.Mode != "":
+		return b.Mode
+	case b.Raw != "":
+		return httpclient.BodyRaw
+	case len(b.Form) > 0:
+		for _, header := range headers {
+			if strings.EqualFold(header.Key, "Content-Type") && strings.HasPrefix(strings.ToLower(header.Value), "multipart/form-data") {
+				return httpclient.BodyMultipart
+			}
+		}
+		return httpclient.BodyURLEncoded
+	}
+	return httpclient.BodyNone
+}
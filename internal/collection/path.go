@@ -0,0 +1,103 @@
+package collection // Package 'collection' models saved requests and the folders and collections that group them
+
+import (
+	"fmt"     // For errors about missing path params
+	"net/url" // For escaping path param values
+	"strings" // For splitting the URL
+)
+
+// Function 'PathParamNames' returns the names of the path params of a URL, in order and without
+// repeats. A path param is a whole path segment written as ':name' or '{name}'; '{{name}}' is an
+// environment variable and is not one.
+func PathParamNames(rawURL string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	eachPathParam(rawURL, func(_ int, name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// Function 'FillPathParams' replaces the path params of a URL with their escaped values. A path
+// param without a value is an error, a URL without path params is returned unchanged.
+func FillPathParams(rawURL string, params []KeyValue) (string, error) {
+	values := make(map[string]string)
+	for _, param := range params {
+		values[param.Key] = param.Value
+	}
+
+	var missing []string
+	filled := &strings.Builder{}
+	last := 0
+	eachPathParam(rawURL, func(start int, name string) {
+		value, ok := values[name]
+		if !ok || value == "" {
+			missing = append(missing, name)
+			return
+		}
+		end := start + len(pathSegment(rawURL[start:]))
+		filled.WriteString(rawURL[last:start])
+		filled.WriteString(url.PathEscape(value))
+		last = end
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no value for path param %s", strings.Join(missing, ", "))
+	}
+	filled.WriteString(rawURL[last:])
+	return filled.String(), nil
+}
+
+// Function 'eachPathParam' calls fn with the offset and name of every path param segment of a URL.
+// Only the path is looked at: the scheme, the host and port, the query and the fragment are skipped.
+func eachPathParam(rawURL string, fn func(start int, name string)) {
+	end := len(rawURL)
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		end = i
+	}
+	start := 0
+	if i := strings.Index(rawURL[:end], "://"); i >= 0 {
+		start = i + len("://")
+	}
+	slash := strings.Index(rawURL[start:end], "/")
+	if slash < 0 {
+		return // No path
+	}
+
+	for offset := start + slash + 1; offset <= end; {
+		segment := pathSegment(rawURL[offset:end])
+		if name := pathParamName(segment); name != "" {
+			fn(offset, name)
+		}
+		offset += len(segment) + 1
+	}
+}
+
+// Function 'pathSegment' returns the text of s up to the next '/', '?' or '#'.
+func pathSegment(s string) string {
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// Function 'pathParamName' returns the name of a ':name' or '{name}' segment, or "" for any other segment.
+func pathParamName(segment string) string {
+	var name string
+	switch {
+	case strings.HasPrefix(segment, ":"):
+		name = segment[1:]
+	case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && !strings.HasPrefix(segment, "{{"):
+		name = segment[1 : len(segment)-1]
+	default:
+		return ""
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return ""
+		}
+	}
+	return name
+}
@@ -0,0 +1,124 @@
+package collection // Package 'collection' models saved requests and the folders and collections that group them
+
+import (
+	"strings" // For splitting the URL
+)
+
+// Function 'SplitURL' splits a URL into the part before the query, the query params and the
+// fragment (including its '#'). Params keep their order, repeated keys, empty keys and values, their
+// raw encoding and whether they had a '=' at all, so joining them again with JoinURL gives back the
+// same URL. Plain string handling is used instead of
+// net/url so URLs with {{variables}} in them work too.
+func SplitURL(rawURL string) (string, []KeyValue, string) {
+	base, fragment := rawURL, ""
+	if i := strings.Index(base, "#"); i >= 0 {
+		base, fragment = base[:i], base[i:]
+	}
+	base, query, hasQuery := strings.Cut(base, "?")
+	if !hasQuery || query == "" {
+		return base, nil, fragment
+	}
+
+	var params []KeyValue
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue // "a=1&&b=2" has no param between the separators
+		}
+		key, value, hasValue := strings.Cut(pair, "=")
+		params = append(params, KeyValue{Key: key, Value: value, NoValue: !hasValue})
+	}
+	return base, params, fragment
+}
+
+// Function 'JoinURL' puts a URL split by SplitURL back together from the enabled params. Keys and
+// values are used as they are, except for the characters that would end them early. A param without
+// a value is written without '=' when it was parsed that way, and rows with neither a key nor a value
+// are left out.
+func JoinURL(base string, params []KeyValue, fragment string) string {
+	var pairs []string
+	for _, param := range params {
+		if param.Disabled || param.IsEmpty() {
+			continue
+		}
+		pair := escapeQueryPart(param.Key, true)
+		if !param.NoValue || param.Value != "" {
+			pair += "=" + escapeQueryPart(param.Value, false)
+		}
+		pairs = append(pairs, pair)
+	}
+	if len(pairs) == 0 {
+		return base + fragment
+	}
+	return base + "?" + strings.Join(pairs, "&") + fragment
+}
+
+// Function 'EnabledParams' returns the params that are sent.
+func EnabledParams(params []KeyValue) []KeyValue {
+	var enabled []KeyValue
+	for _, param := range params {
+		if !param.Disabled {
+			enabled = append(enabled, param)
+		}
+	}
+	return enabled
+}
+
+// Function 'MergeParams' rebuilds the param rows after the query of a URL was edited. The query
+// gives the enabled rows in its order; disabled rows are not in the URL, so they stay where they
+// were between the rows read from it. Enabled rows with neither a key nor a value are dropped.
+func MergeParams(rows, query []KeyValue) []KeyValue {
+	var merged []KeyValue
+	next := 0
+	for _, row := range rows {
+		switch {
+		case row.Disabled:
+			merged = append(merged, row)
+		case !row.IsEmpty() && next < len(query):
+			merged = append(merged, query[next])
+			next++
+		}
+	}
+	return append(merged, query[next:]...)
+}
+
+// Function 'RestoreParams' returns the param rows of a saved request. The saved params are used when
+// their enabled rows still match the query of the URL, which brings back the disabled rows;
+// otherwise the URL was edited by hand and its query gives the rows.
+func RestoreParams(rawURL string, saved []KeyValue) []KeyValue {
+	_, query, _ := SplitURL(rawURL)
+	if !sameParams(EnabledParams(saved), query) {
+		return query
+	}
+	rows := append([]KeyValue(nil), saved...)
+	next := 0
+	for i := range rows {
+		if !rows[i].Disabled {
+			rows[i].NoValue = query[next].NoValue // Requests saved before NoValue existed take it from the URL
+			next++
+		}
+	}
+	return rows
+}
+
+// Function 'sameParams' reports whether two param lists have the same keys and values in the same order.
+func sameParams(a, b []KeyValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Function 'escapeQueryPart' escapes what would break a query apart: '&' and '#' everywhere, '='
+// in keys and spaces. Anything else, percent escapes included, is kept as typed.
+func escapeQueryPart(s string, key bool) string {
+	replacements := []string{"&", "%26", "#", "%23", " ", "%20"}
+	if key {
+		replacements = append(replacements, "=", "%3D")
+	}
+	return strings.NewReplacer(replacements...).Replace(s)
+}
@@ -0,0 +1,214 @@
+package collection
+
+import (
+	"reflect" // For comparing param lists
+	"testing" // Go test framework
+)
+
+// Function 'TestSplitJoinURL' checks that splitting a URL and joining it again gives back the same URL.
+func TestSplitJoinURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		base     string
+		params   []KeyValue
+		fragment string
+	}{
+		{
+			name: "no query",
+			url:  "https://example.com/users",
+			base: "https://example.com/users",
+		},
+		{
+			name:   "duplicate keys",
+			url:    "https://example.com/?a=1&b=2&a=3",
+			base:   "https://example.com/",
+			params: []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "a", Value: "3"}},
+		},
+		{
+			name:   "empty value",
+			url:    "https://example.com/?a=&b=2",
+			base:   "https://example.com/",
+			params: []KeyValue{{Key: "a"}, {Key: "b", Value: "2"}},
+		},
+		{
+			name:   "valueless param",
+			url:    "https://example.com/?flag&b=2",
+			base:   "https://example.com/",
+			params: []KeyValue{{Key: "flag", NoValue: true}, {Key: "b", Value: "2"}},
+		},
+		{
+			name:   "empty key",
+			url:    "https://example.com/?=x",
+			base:   "https://example.com/",
+			params: []KeyValue{{Value: "x"}},
+		},
+		{
+			name:   "raw percent encoding",
+			url:    "https://example.com/?q=a%20b%2Bc&tilde=%7e&plus=a+b",
+			base:   "https://example.com/",
+			params: []KeyValue{{Key: "q", Value: "a%20b%2Bc"}, {Key: "tilde", Value: "%7e"}, {Key: "plus", Value: "a+b"}},
+		},
+		{
+			name:     "fragment",
+			url:      "https://example.com/?a=1#section?b=2",
+			base:     "https://example.com/",
+			params:   []KeyValue{{Key: "a", Value: "1"}},
+			fragment: "#section?b=2",
+		},
+		{
+			name:     "fragment without query",
+			url:      "https://example.com/page#top",
+			base:     "https://example.com/page",
+			fragment: "#top",
+		},
+		{
+			name:   "variables",
+			url:    "{{host}}/users?id={{id}}&token",
+			base:   "{{host}}/users",
+			params: []KeyValue{{Key: "id", Value: "{{id}}"}, {Key: "token", NoValue: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, params, fragment := SplitURL(test.url)
+			if base != test.base || fragment != test.fragment {
+				t.Errorf("SplitURL(%q) = %q, %q, want %q, %q", test.url, base, fragment, test.base, test.fragment)
+			}
+			if !reflect.DeepEqual(params, test.params) {
+				t.Errorf("SplitURL(%q) params = %+v, want %+v", test.url, params, test.params)
+			}
+			if joined := JoinURL(base, params, fragment); joined != test.url {
+				t.Errorf("JoinURL(SplitURL(%q)) = %q", test.url, joined)
+			}
+		})
+	}
+}
+
+// Function 'TestJoinURL' checks the rows that are left out and the characters that are escaped.
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []KeyValue
+		want   string
+	}{
+		{
+			name:   "disabled rows",
+			params: []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}},
+			want:   "https://example.com/?a=1",
+		},
+		{
+			name:   "empty rows",
+			params: []KeyValue{{}, {Key: "a", Value: "1"}, {}},
+			want:   "https://example.com/?a=1",
+		},
+		{
+			name:   "only disabled rows",
+			params: []KeyValue{{Key: "a", Value: "1", Disabled: true}},
+			want:   "https://example.com/",
+		},
+		{
+			name:   "typed value drops NoValue",
+			params: []KeyValue{{Key: "flag", Value: "on", NoValue: true}},
+			want:   "https://example.com/?flag=on",
+		},
+		{
+			name:   "separators escaped",
+			params: []KeyValue{{Key: "a=b", Value: "x&y#z w=v"}},
+			want:   "https://example.com/?a%3Db=x%26y%23z%20w=v",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := JoinURL("https://example.com/", test.params, ""); got != test.want {
+				t.Errorf("JoinURL(%+v) = %q, want %q", test.params, got, test.want)
+			}
+		})
+	}
+}
+
+// Function 'TestMergeParams' checks that disabled rows keep their place when the URL is edited.
+func TestMergeParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []KeyValue
+		query []KeyValue
+		want  []KeyValue
+	}{
+		{
+			name:  "value edited",
+			rows:  []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}, {Key: "c", Value: "3"}},
+			query: []KeyValue{{Key: "a", Value: "9"}, {Key: "c", Value: "3"}},
+			want:  []KeyValue{{Key: "a", Value: "9"}, {Key: "b", Value: "2", Disabled: true}, {Key: "c", Value: "3"}},
+		},
+		{
+			name:  "param added",
+			rows:  []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}},
+			query: []KeyValue{{Key: "a", Value: "1"}, {Key: "d", NoValue: true}},
+			want:  []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}, {Key: "d", NoValue: true}},
+		},
+		{
+			name:  "param removed",
+			rows:  []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}, {Key: "c", Value: "3"}},
+			query: []KeyValue{{Key: "a", Value: "1"}},
+			want:  []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}},
+		},
+		{
+			name:  "empty rows dropped",
+			rows:  []KeyValue{{}, {Key: "a", Value: "1"}, {}},
+			query: []KeyValue{{Key: "a", Value: "1"}},
+			want:  []KeyValue{{Key: "a", Value: "1"}},
+		},
+		{
+			name:  "query cleared",
+			rows:  []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}},
+			query: nil,
+			want:  []KeyValue{{Key: "b", Value: "2", Disabled: true}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MergeParams(test.rows, test.query); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("MergeParams() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// Function 'TestRestoreParams' checks which rows a saved request is loaded with.
+func TestRestoreParams(t *testing.T) {
+	saved := []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}, {Key: "flag"}}
+
+	tests := []struct {
+		name string
+		url  string
+		want []KeyValue
+	}{
+		{
+			name: "URL matches the saved params",
+			url:  "https://example.com/?a=1&flag",
+			want: []KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2", Disabled: true}, {Key: "flag", NoValue: true}},
+		},
+		{
+			name: "URL edited by hand",
+			url:  "https://example.com/?a=5&flag",
+			want: []KeyValue{{Key: "a", Value: "5"}, {Key: "flag", NoValue: true}},
+		},
+		{
+			name: "query removed",
+			url:  "https://example.com/",
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := RestoreParams(test.url, saved); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("RestoreParams(%q) = %+v, want %+v", test.url, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package collection // Package 'collection' models saved requests and the folders and collections that group them
+
+import (
+	"encoding/json" // For the JSON file format
+	"fmt"           // For wrapping errors
+	"os"            // For reading and writing collection files
+	"path/filepath" // For building file names
+	"sort"          // For listing collections in a stable order
+	"strings"       // For file extension checks
+
+	"gopkg.in/yaml.v3" // For the YAML file format
+
+	"github.com/SiirRandall/go-restful/internal/config" // Config directory lookup
+)
+
+// Function 'Dir' returns the directory collections are stored in.
+func Dir() (string, error) {
+	return config.SubDir("collections")
+}
+
+// Function 'New' creates an empty collection that will be saved as name.json in the collections directory.
+func New(name string) (*Collection, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Collection{
+		Folder: Folder{Name: name},
+		Path:   filepath.Join(dir, fileName(name)+".json"),
+	}, nil
+}
+
+// Function 'LoadAll' loads every JSON and YAML collection in the collections directory.
+func LoadAll() ([]*Collection, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var collections []*Collection
+	for _, entry := range entries {
+		if entry.IsDir() || !isCollectionFile(entry.Name()) {
+			continue
+		}
+		c, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return collections, err
+		}
+		collections = append(collections, c)
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return strings.ToLower(collections[i].Name) < strings.ToLower(collections[j].Name)
+	})
+	return collections, nil
+}
+
+// Function 'Load' reads a collection from a JSON or YAML file, picking the format from the extension.
+func Load(path string) (*Collection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Collection{Path: path}
+	if isYAML(path) {
+		err = yaml.Unmarshal(data, c)
+	} else {
+		err = json.Unmarshal(data, c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing collection %s: %w", path, err)
+	}
+
+	if c.Name == "" { // Fall back to the file name for hand written files
+		c.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	c.Walk(func(_ []string, r *Request) {
+		r.Upgrade() // Older files store the token and the body differently
+	})
+	return c, nil
+}
+
+// Function 'Find' loads a collection by the path of its file, or by name from the collections
+// directory, ignoring case.
+func Find(nameOrPath string) (*Collection, error) {
+	if info, err := os.Stat(nameOrPath); err == nil && !info.IsDir() {
+		return Load(nameOrPath)
+	}
+	collections, err := LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range collections {
+		if strings.EqualFold(c.Name, nameOrPath) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no collection named %q", nameOrPath)
+}
+
+// Function 'Save' writes the collection back to its file in the format given by the extension.
+func (c *Collection) Save() error {
+	var (
+		data []byte
+		err  error
+	)
+	if isYAML(c.Path) {
+		data, err = yaml.Marshal(c)
+	} else {
+		data, err = json.MarshalIndent(c, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.Path, data, 0o600)
+}
+
+// Function 'Delete' removes the collection file from disk.
+func (c *Collection) Delete() error {
+	return os.Remove(c.Path)
+}
+
+// Function 'isCollectionFile' reports whether a file name has a supported collection extension.
+func isCollectionFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".json" || ext == ".yaml" || ext == ".yml"
+}
+
+// Function 'isYAML' reports whether a path should be read and written as YAML.
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Function 'fileName' turns a collection name into a safe file name.
+func fileName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "collection"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, name)
+}
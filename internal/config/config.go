@@ -0,0 +1,39 @@
+package config // Package 'config' locates the directories where go-restful keeps its files
+
+import (
+	"os"            // For looking up the user config directory and creating folders
+	"path/filepath" // For joining path segments in an OS independent way
+)
+
+// AppName is the name of the directory created under the user config directory.
+const AppName = "go-restful"
+
+// Function 'Dir' returns the go-restful config directory, creating it when needed.
+// The GO_RESTFUL_CONFIG_DIR environment variable overrides the default location.
+func Dir() (string, error) {
+	dir := os.Getenv("GO_RESTFUL_CONFIG_DIR")
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(base, AppName)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Function 'SubDir' returns a named directory inside the config directory, creating it when needed.
+func SubDir(name string) (string, error) {
+	base, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, name)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
@@ -0,0 +1,284 @@
+package cookies // Package 'cookies' keeps the cookies responses set, one jar per environment, and sends them back
+
+import (
+	"net"      // For telling IP addresses from host names
+	"net/http" // For the cookie jar interface and parsed Set-Cookie values
+	"net/url"  // For the request URLs cookies are matched against
+	"sort"     // For the order cookies are sent and listed in
+	"strings"  // For domain and path matching
+	"time"     // For expiry
+
+	"golang.org/x/net/publicsuffix" // For refusing cookies set on a public suffix such as co.uk
+)
+
+// 'Cookie' is a cookie stored in a jar.
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`             // Host, or domain with its subdomains unless HostOnly
+	Path     string    `json:"path"`               // Sent to this path and the paths below it
+	Expires  time.Time `json:"expires"`            // Zero for a session cookie, kept until the jar is cleared
+	Secure   bool      `json:"secure,omitempty"`   // Only sent over HTTPS
+	HttpOnly bool      `json:"httpOnly,omitempty"` // Kept for display, every request of the client is HTTP
+	HostOnly bool      `json:"hostOnly,omitempty"` // Set without a Domain attribute: sent to that exact host only
+	Created  time.Time `json:"created"`            // When the cookie was first set, older cookies are sent first
+}
+
+// 'Jar' holds the cookies of one environment. It implements http.CookieJar, so the client stores
+// what every response sets and adds the matching cookies to every request.
+type Jar struct {
+	cookies    []Cookie
+	persistent bool // Saved to the cookies file on every change
+}
+
+// Function 'New' returns an empty jar kept in memory only, as for a run of a collection.
+func New() *Jar {
+	return &Jar{}
+}
+
+// Function 'SetCookies' stores the cookies a response from u set, following the domain, path,
+// expiry and secure rules of RFC 6265. Cookies a response may not set are ignored, and a cookie
+// that already expired deletes the stored one.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	kept := j.cookies[:0]
+	for _, cookie := range j.cookies { // Drop what expired since the last response
+		if !cookie.expired(now) {
+			kept = append(kept, cookie)
+		}
+	}
+	changed := len(kept) < len(j.cookies)
+	j.cookies = kept
+	for _, c := range cookies {
+		cookie, ok := fromResponse(u, c, now)
+		if !ok {
+			continue
+		}
+		index := j.find(cookie)
+		switch {
+		case !cookie.Expires.IsZero() && !cookie.Expires.After(now):
+			if index >= 0 {
+				j.cookies = append(j.cookies[:index], j.cookies[index+1:]...)
+			}
+		case index >= 0:
+			cookie.Created = j.cookies[index].Created
+			j.cookies[index] = cookie
+		default:
+			j.cookies = append(j.cookies, cookie)
+		}
+		changed = true
+	}
+	if changed {
+		j.save() // Cookies stay in memory when the file cannot be written, the next change retries
+	}
+}
+
+// Function 'Cookies' returns the cookies to send to u: those whose domain and path match it, that
+// did not expire and, for Secure ones, only over HTTPS. Longer paths come first, then older cookies.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	host := canonicalHost(u)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	var matching []Cookie
+	for _, cookie := range j.cookies {
+		if cookie.expired(now) || cookie.Secure && u.Scheme != "https" {
+			continue
+		}
+		if cookie.HostOnly && host != cookie.Domain || !cookie.HostOnly && !domainMatch(host, cookie.Domain) {
+			continue
+		}
+		if pathMatch(path, cookie.Path) {
+			matching = append(matching, cookie)
+		}
+	}
+	sort.SliceStable(matching, func(a, b int) bool {
+		if len(matching[a].Path) != len(matching[b].Path) {
+			return len(matching[a].Path) > len(matching[b].Path)
+		}
+		return matching[a].Created.Before(matching[b].Created)
+	})
+
+	cookies := make([]*http.Cookie, 0, len(matching))
+	for _, cookie := range matching {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return cookies
+}
+
+// Function 'List' returns a copy of the cookies that did not expire, by domain, path and name.
+func (j *Jar) List() []Cookie {
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	var cookies []Cookie
+	for _, cookie := range j.cookies {
+		if !cookie.expired(now) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	sort.SliceStable(cookies, func(a, b int) bool {
+		switch {
+		case cookies[a].Domain != cookies[b].Domain:
+			return cookies[a].Domain < cookies[b].Domain
+		case cookies[a].Path != cookies[b].Path:
+			return cookies[a].Path < cookies[b].Path
+		}
+		return cookies[a].Name < cookies[b].Name
+	})
+	return cookies
+}
+
+// Function 'Put' adds a cookie, or replaces the one with the same name, domain and path as
+// previous. Pass the cookie itself as previous to add it or to keep its identity.
+func (j *Jar) Put(previous, cookie Cookie) error {
+	mu.Lock()
+	defer mu.Unlock()
+	cookie.Domain = strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if index := j.find(previous); index >= 0 {
+		cookie.Created = j.cookies[index].Created
+		j.cookies = append(j.cookies[:index], j.cookies[index+1:]...)
+	}
+	if index := j.find(cookie); index >= 0 { // The edit may collide with another cookie
+		j.cookies = append(j.cookies[:index], j.cookies[index+1:]...)
+	}
+	if cookie.Created.IsZero() {
+		cookie.Created = time.Now()
+	}
+	j.cookies = append(j.cookies, cookie)
+	return j.save()
+}
+
+// Function 'Delete' removes the cookie with the name, domain and path of cookie.
+func (j *Jar) Delete(cookie Cookie) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if index := j.find(cookie); index >= 0 {
+		j.cookies = append(j.cookies[:index], j.cookies[index+1:]...)
+	}
+	return j.save()
+}
+
+// Function 'Clear' removes every cookie of the jar.
+func (j *Jar) Clear() error {
+	mu.Lock()
+	defer mu.Unlock()
+	j.cookies = nil
+	return j.save()
+}
+
+// Function 'find' returns the index of the cookie with the name, domain and path of cookie, or -1.
+func (j *Jar) find(cookie Cookie) int {
+	for i, stored := range j.cookies {
+		if stored.Name == cookie.Name && stored.Domain == cookie.Domain && stored.Path == cookie.Path {
+			return i
+		}
+	}
+	return -1
+}
+
+// Function 'save' writes the jar when it is persistent. The caller holds mu.
+func (j *Jar) save() error {
+	if !j.persistent {
+		return nil
+	}
+	return saveJars()
+}
+
+// Function 'expired' reports whether the cookie expired at now. Session cookies never do.
+func (c Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// Function 'fromResponse' turns a cookie set by a response from u into a stored cookie. It reports
+// false when the response may not set it: a Domain the host is not part of, a Domain on an IP
+// address or a public suffix such as com or co.uk, or a Secure cookie over plain HTTP.
+func fromResponse(u *url.URL, c *http.Cookie, now time.Time) (Cookie, bool) {
+	host := canonicalHost(u)
+	cookie := Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		Created:  now,
+	}
+	if cookie.Name == "" || host == "" || cookie.Secure && u.Scheme != "https" {
+		return cookie, false
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	switch {
+	case domain == "" || domain == host:
+		cookie.Domain, cookie.HostOnly = host, domain == ""
+	case net.ParseIP(host) != nil || !domainMatch(host, domain) || publicSuffix(domain):
+		return cookie, false
+	default:
+		cookie.Domain = domain
+	}
+
+	if !strings.HasPrefix(cookie.Path, "/") {
+		cookie.Path = defaultPath(u.EscapedPath())
+	}
+
+	switch {
+	case c.MaxAge < 0: // Max-Age=0 or less: delete the cookie
+		cookie.Expires = now
+	case c.MaxAge > 0:
+		cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		cookie.Expires = c.Expires
+	}
+	return cookie, true
+}
+
+// Function 'publicSuffix' reports whether domain is a public suffix, under which anyone can register
+// a name. Names unknown to the list are treated as top level domains.
+func publicSuffix(domain string) bool {
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix == domain
+}
+
+// Function 'canonicalHost' returns the host name of u in lower case, without the port.
+func canonicalHost(u *url.URL) string {
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
+
+// Function 'domainMatch' reports whether host is domain or one of its subdomains. IP addresses only
+// match themselves.
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// Function 'pathMatch' reports whether a request path is the cookie path or below it.
+func pathMatch(path, cookiePath string) bool {
+	switch {
+	case path == cookiePath:
+		return true
+	case strings.HasPrefix(path, cookiePath):
+		return strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+	}
+	return false
+}
+
+// Function 'defaultPath' is the path of a cookie set without one: the directory of the request path.
+func defaultPath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
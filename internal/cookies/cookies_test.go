@@ -0,0 +1,63 @@
+package cookies
+
+import (
+	"net/http" // For the cookies a response sets
+	"net/url"  // For the URLs of the responses
+	"testing"  // Go test framework
+	"time"     // For the time the cookies are set at
+)
+
+// Function 'TestFromResponseDomain' checks which Domain attributes a response may set.
+func TestFromResponseDomain(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		domain       string
+		wantOK       bool
+		wantDomain   string
+		wantHostOnly bool
+	}{
+		{"no domain", "https://api.example.com/", "", true, "api.example.com", true},
+		{"own host", "https://api.example.com/", "api.example.com", true, "api.example.com", false},
+		{"parent domain", "https://api.example.com/", "example.com", true, "example.com", false},
+		{"leading dot", "https://api.example.com/", ".Example.com", true, "example.com", false},
+		{"other domain", "https://api.example.com/", "example.org", false, "", false},
+		{"top level domain", "https://api.example.com/", "com", false, "", false},
+		{"public suffix", "https://evil.co.uk/", "co.uk", false, "", false},
+		{"private public suffix", "https://evil.github.io/", "github.io", false, "", false},
+		{"below a public suffix", "https://www.shop.co.uk/", "shop.co.uk", true, "shop.co.uk", false},
+		{"single label host", "http://localhost:8080/", "localhost", true, "localhost", false},
+		{"unknown top level domain", "http://app.internal/", "internal", false, "", false},
+		{"IP address", "http://10.0.0.1/", "0.0.1", false, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cookie, ok := fromResponse(u, &http.Cookie{Name: "session", Value: "1", Domain: test.domain}, time.Now())
+			if ok != test.wantOK {
+				t.Fatalf("fromResponse(Domain=%q) ok = %v, want %v", test.domain, ok, test.wantOK)
+			}
+			if ok && (cookie.Domain != test.wantDomain || cookie.HostOnly != test.wantHostOnly) {
+				t.Errorf("fromResponse(Domain=%q) = domain %q, host only %v, want %q, %v",
+					test.domain, cookie.Domain, cookie.HostOnly, test.wantDomain, test.wantHostOnly)
+			}
+		})
+	}
+}
+
+// Function 'TestJarPublicSuffix' checks that a cookie refused for a public suffix is not sent to
+// the other hosts under it.
+func TestJarPublicSuffix(t *testing.T) {
+	jar := New()
+	evil, _ := url.Parse("https://evil.co.uk/")
+	jar.SetCookies(evil, []*http.Cookie{{Name: "tracker", Value: "1", Domain: "co.uk"}})
+
+	victim, _ := url.Parse("https://bank.co.uk/")
+	if cookies := jar.Cookies(victim); len(cookies) != 0 {
+		t.Errorf("Cookies(%s) = %v, want none", victim, cookies)
+	}
+}
@@ -0,0 +1,95 @@
+package cookies // Package 'cookies' keeps the cookies responses set, one jar per environment, and sends them back
+
+import (
+	"encoding/json" // For the cookies file format
+	"errors"        // For detecting a missing cookies file
+	"io/fs"         // For the not-exist error
+	"os"            // For reading and writing the cookies file
+	"path/filepath" // For building the file name
+	"sync"          // For serializing access from concurrent requests
+
+	"github.com/SiirRandall/go-restful/internal/config" // Config directory lookup
+)
+
+// FileName is the name of the file inside the config directory that keeps the jars between runs.
+const FileName = "cookies.json"
+
+var (
+	mu   sync.Mutex      // Guards every jar and the cookies file
+	jars map[string]*Jar // Persistent jars by environment name, "" when none is active; nil until the file was read
+)
+
+// Function 'Path' returns the location of the cookies file.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Function 'For' returns the jar of an environment, "" for the jar used when none is active. The
+// jar is saved to the cookies file on every change. When the file cannot be read, an empty jar is
+// returned along with the error.
+func For(environment string) (*Jar, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	err := loadJars()
+	if err != nil && jars == nil {
+		jars = make(map[string]*Jar) // Replace an unreadable file rather than failing every request
+	}
+	jar := jars[environment]
+	if jar == nil {
+		jar = &Jar{persistent: true}
+		jars[environment] = jar
+	}
+	return jar, err
+}
+
+// Function 'loadJars' reads the cookies file once. A missing file holds no cookies.
+func loadJars() error {
+	if jars != nil {
+		return nil
+	}
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		jars = make(map[string]*Jar)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	stored := make(map[string][]Cookie)
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	jars = make(map[string]*Jar, len(stored))
+	for environment, cookies := range stored {
+		jars[environment] = &Jar{cookies: cookies, persistent: true}
+	}
+	return nil
+}
+
+// Function 'saveJars' writes every persistent jar to the cookies file, readable by the user only as
+// cookies often hold sessions. Empty jars are left out.
+func saveJars() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	stored := make(map[string][]Cookie, len(jars))
+	for environment, jar := range jars {
+		if len(jar.cookies) > 0 {
+			stored[environment] = jar.cookies
+		}
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
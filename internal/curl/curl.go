@@ -0,0 +1,404 @@
+package curl // Package 'curl' imports requests from curl command lines
+
+import (
+	"errors"  // For parse errors
+	"fmt"     // For formatted errors
+	"net/url" // For appending -G data to the query string
+	"os"      // For reading @file data arguments
+	"strconv" // For numeric option values
+	"strings" // For string manipulations
+
+	"github.com/SiirRandall/go-restful/internal/auth"                  // Authentication schemes module
+	"github.com/SiirRandall/go-restful/internal/collection"            // Saved requests module
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// optionsWithValue lists the curl options that take an argument but have no meaning for an import.
+// They are skipped together with their argument.
+var optionsWithValue = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "-w": true, "--write-out": true,
+	"--retry": true, "-c": true, "--cookie-jar": true, "-r": true,
+	"--range": true, "--resolve": true, "-K": true, "--config": true, "--limit-rate": true,
+}
+
+// shortOptionsWithValue lists the single letter options that take an argument, whether handled or skipped.
+const shortOptionsWithValue = "XHdeuFAbomwxcTrEK"
+
+// Function 'Parse' turns a curl command line into a request. It understands the options that
+// browsers and API docs commonly emit: -X, -H, -d/--data-raw/--data-binary/--data-urlencode, -u,
+// -F, -T, -G, -I, -A, -e, -b, --compressed and shell quoting. Connection options (-k, -L, --max-redirs,
+// -x/--proxy, --socks5, --cacert, -E/--cert, --key, --connect-timeout) become settings overrides.
+// -u credentials become Basic auth, Digest auth with --digest or AWS signing with --aws-sigv4, and
+// --oauth2-bearer a Bearer token.
+func Parse(command string) (*collection.Request, error) {
+	args, err := Split(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("not a curl command")
+	}
+
+	request := &collection.Request{}
+	var (
+		data       []string
+		form       []collection.KeyValue
+		uploadFile string // -T: file sent as the body
+		useQuery   bool   // -G: send the data in the query string
+		isHead     bool
+
+		user       *string // -u credentials, applied to the scheme picked by the other options
+		authType   = auth.TypeBasic
+		awsRegion  string
+		awsService string
+	)
+	addHeader := func(key, value string) {
+		request.Headers = append(request.Headers, collection.KeyValue{Key: key, Value: value})
+	}
+	settings := func() *httpclient.SettingsOverride {
+		if request.Settings == nil {
+			request.Settings = &httpclient.SettingsOverride{}
+		}
+		return request.Settings
+	}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		// Fetch the argument of an option, either attached (-XPOST) or the next word
+		name, value, hasValue := splitOption(arg)
+		next := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s needs a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		// Bundled short flags such as -sSLk
+		if name == "" && len(arg) > 2 && arg[0] == '-' && arg[1] != '-' {
+			for _, flag := range arg[1:] {
+				switch flag {
+				case 'k':
+					settings().InsecureSkipVerify = enabled()
+				case 'L':
+					settings().FollowRedirects = enabled()
+				case 'G':
+					useQuery = true
+				case 'I':
+					isHead = true
+				}
+			}
+			continue
+		}
+
+		switch name {
+		case "":
+			if strings.HasPrefix(arg, "-") {
+				continue // Flags without arguments we do not care about (-s, -v, -L, -i...)
+			}
+			if request.URL == "" {
+				request.URL = arg
+			}
+		case "--url":
+			if request.URL, err = next(); err != nil {
+				return nil, err
+			}
+		case "-X", "--request":
+			if request.Method, err = next(); err != nil {
+				return nil, err
+			}
+		case "-H", "--header":
+			header, err := next()
+			if err != nil {
+				return nil, err
+			}
+			key, value, _ := strings.Cut(header, ":")
+			addHeader(strings.TrimSpace(key), strings.TrimSpace(value))
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw", "--data-urlencode":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if value, err = dataValue(name, value); err != nil {
+				return nil, err
+			}
+			data = append(data, value)
+		case "-F", "--form":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			key, fieldValue, _ := strings.Cut(value, "=")
+			field := collection.KeyValue{Key: key, Value: fieldValue}
+			if strings.HasPrefix(fieldValue, "@") { // "@path;type=..." sends a file, its type is guessed from the extension
+				field.Value, _, _ = strings.Cut(strings.TrimPrefix(fieldValue, "@"), ";")
+				field.File = true
+			}
+			form = append(form, field)
+		case "-T", "--upload-file":
+			if uploadFile, err = next(); err != nil {
+				return nil, err
+			}
+		case "-u", "--user":
+			credentials, err := next()
+			if err != nil {
+				return nil, err
+			}
+			user = &credentials
+		case "--oauth2-bearer":
+			token, err := next()
+			if err != nil {
+				return nil, err
+			}
+			request.Auth = &auth.Auth{Type: auth.TypeBearer, Token: token}
+		case "--basic":
+			authType = auth.TypeBasic
+		case "--digest":
+			authType = auth.TypeDigest
+		case "--aws-sigv4":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			// "provider1[:provider2[:region[:service]]]", the region and service are taken from the host otherwise
+			parts := strings.Split(value, ":")
+			authType = auth.TypeAWSV4
+			if len(parts) > 2 {
+				awsRegion = parts[2]
+			}
+			if len(parts) > 3 {
+				awsService = parts[3]
+			}
+		case "-A", "--user-agent":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			addHeader("User-Agent", value)
+		case "-e", "--referer":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			addHeader("Referer", value)
+		case "-b", "--cookie":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			addHeader("Cookie", value)
+		case "-k", "--insecure":
+			settings().InsecureSkipVerify = enabled()
+		case "-L", "--location":
+			settings().FollowRedirects = enabled()
+		case "--max-redirs":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			hops, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --max-redirs %q", value)
+			}
+			settings().MaxRedirects = &hops
+		case "-x", "--proxy", "--socks5", "--socks5-hostname":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(name, "--socks5") && !strings.Contains(value, "://") {
+				value = "socks5://" + value
+			}
+			settings().Proxy = &value
+		case "--cacert":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			settings().CACertFile = &value
+		case "-E", "--cert":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			value, _, _ = strings.Cut(value, ":") // A ":password" suffix is for encrypted keys, which are not supported
+			settings().ClientCertFile = &value
+		case "--key":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			settings().ClientKeyFile = &value
+		case "--connect-timeout":
+			value, err := next()
+			if err != nil {
+				return nil, err
+			}
+			timeout, err := httpclient.ParseDuration(value) // curl takes (fractional) seconds
+			if err != nil {
+				return nil, err
+			}
+			duration := httpclient.Duration(timeout)
+			settings().ConnectTimeout = &duration
+		case "-G", "--get":
+			useQuery = true
+		case "-I", "--head":
+			isHead = true
+		case "--compressed":
+			// curl only negotiates compression and decodes the answer, the response we show is the same without it
+		default:
+			if optionsWithValue[name] {
+				if _, err := next(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if request.URL == "" {
+		return nil, errors.New("no URL found in curl command")
+	}
+	if !strings.Contains(request.URL, "://") {
+		request.URL = "http://" + request.URL // curl assumes http when the scheme is missing
+	}
+
+	if user != nil {
+		username, password, _ := strings.Cut(*user, ":")
+		if authType == auth.TypeAWSV4 {
+			service, region := awsHostScope(request.URL)
+			if awsService != "" {
+				service = awsService
+			}
+			if awsRegion != "" {
+				region = awsRegion
+			}
+			request.Auth = &auth.Auth{Type: authType, AccessKey: username, SecretKey: password, Region: region, Service: service}
+		} else {
+			request.Auth = &auth.Auth{Type: authType, Username: username, Password: password}
+		}
+	}
+
+	// Attach the data the way curl would
+	request.Body.Mode = httpclient.BodyNone
+	switch {
+	case uploadFile != "":
+		request.Body = collection.Body{Mode: httpclient.BodyBinary, File: uploadFile}
+	case len(form) > 0:
+		request.Body = collection.Body{Mode: httpclient.BodyMultipart, Form: form}
+	case len(data) > 0 && useQuery:
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		request.URL += separator + strings.Join(data, "&")
+	case len(data) > 0:
+		request.Body = collection.Body{Mode: httpclient.BodyRaw, Raw: strings.Join(data, "&")}
+		if !hasHeader(request.Headers, "Content-Type") {
+			addHeader("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+
+	// Pick the method curl would use when none was given
+	if request.Method == "" {
+		switch {
+		case isHead:
+			request.Method = "HEAD"
+		case uploadFile != "":
+			request.Method = "PUT"
+		case (len(data) > 0 && !useQuery) || len(form) > 0:
+			request.Method = "POST"
+		default:
+			request.Method = "GET"
+		}
+	}
+
+	return request, nil
+}
+
+// Function 'awsHostScope' guesses the service and region from an AWS host name such as
+// "sqs.eu-west-1.amazonaws.com", the way curl does when --aws-sigv4 leaves them out.
+func awsHostScope(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	if len(labels) < 2 {
+		return "", ""
+	}
+	return labels[0], labels[1]
+}
+
+// Function 'splitOption' splits an argument into its option name and an attached value.
+// "--data=x" gives ("--data", "x", true), "-XPOST" gives ("-X", "POST", true) and "-H" gives ("-H", "", false).
+// Arguments that are not options, and bundles of short flags without values, give an empty name.
+func splitOption(arg string) (string, string, bool) {
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			return name, value, true
+		}
+		return arg, "", false
+	case len(arg) >= 2 && arg[0] == '-':
+		name := arg[:2]
+		if len(arg) == 2 {
+			return name, "", false
+		}
+		if strings.ContainsRune(shortOptionsWithValue, rune(arg[1])) {
+			return name, arg[2:], true
+		}
+		return "", "", false // A bundle such as -sSL
+	default:
+		return "", "", false
+	}
+}
+
+// Function 'dataValue' applies the per-option rules for data arguments: -d and --data-binary read
+// "@file", --data-urlencode encodes its content and --data-raw is taken literally.
+func dataValue(option, value string) (string, error) {
+	switch option {
+	case "--data-raw":
+		return value, nil
+	case "--data-urlencode":
+		key, content, hasKey := strings.Cut(value, "=")
+		if !hasKey {
+			return url.QueryEscape(value), nil
+		}
+		if key == "" {
+			return url.QueryEscape(content), nil
+		}
+		return key + "=" + url.QueryEscape(content), nil
+	}
+
+	if strings.HasPrefix(value, "@") {
+		content, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return "", fmt.Errorf("reading data file: %w", err)
+		}
+		value = string(content)
+		if option != "--data-binary" { // Plain -d strips newlines from files
+			value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		}
+	}
+	return value, nil
+}
+
+// Function 'hasHeader' reports whether a header is present, ignoring the case of its name.
+func hasHeader(headers []collection.KeyValue, name string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(header.Key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function 'enabled' returns a pointer to a fresh true value, for boolean settings overrides.
+func enabled() *bool {
+	value := true
+	return &value
+}
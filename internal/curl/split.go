@@ -0,0 +1,141 @@
+package curl // Package 'curl' imports requests from curl command lines
+
+import (
+	"errors"  // For reporting unterminated quotes
+	"strconv" // For decoding \x and \u escapes in $'...' strings
+	"strings" // For building words
+)
+
+// Function 'Split' breaks a command line into words the way a POSIX shell would. It supports
+// single quotes, double quotes, backslash escapes, line continuations and the $'...' strings that
+// browsers use in "Copy as cURL".
+func Split(command string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+	)
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' { // Line continuation
+					continue
+				}
+				if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+					continue
+				}
+				current.WriteRune(runes[i])
+				inWord = true
+			}
+		case r == '\'':
+			end := indexRune(runes, '\'', i+1)
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			value, end, err := ansiCString(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			inWord = true
+			i = end
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				// Inside double quotes a backslash only escapes these characters
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// Function 'indexRune' returns the index of the first r in runes at or after start, or -1.
+func indexRune(runes []rune, r rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// Function 'ansiCString' decodes the body of a $'...' string starting at start.
+// It returns the decoded text and the index of the closing quote.
+func ansiCString(runes []rune, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return b.String(), i, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				return "", 0, errors.New("unterminated $' string")
+			}
+			i++
+			switch c := runes[i]; c {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'x', 'u', 'U':
+				// Hex escapes: \xHH, \uHHHH, \UHHHHHHHH
+				size := map[rune]int{'x': 2, 'u': 4, 'U': 8}[c]
+				end := i + 1
+				for end < len(runes) && end < i+1+size && strings.ContainsRune("0123456789abcdefABCDEF", runes[end]) {
+					end++
+				}
+				code, err := strconv.ParseUint(string(runes[i+1:end]), 16, 32)
+				if err != nil {
+					b.WriteRune(c)
+					continue
+				}
+				if c == 'x' {
+					b.WriteByte(byte(code))
+				} else {
+					b.WriteRune(rune(code))
+				}
+				i = end - 1
+			default: // \\, \', \" and anything else stand for themselves
+				b.WriteRune(c)
+			}
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+	return "", 0, errors.New("unterminated $' string")
+}
@@ -0,0 +1,103 @@
+package environment // Package 'environment' stores named sets of variables and substitutes them into requests
+
+import (
+	"regexp"  // For finding {{var}} placeholders
+	"strings" // For trimming variable names
+
+	"github.com/SiirRandall/go-restful/internal/collection" // Saved requests module
+	"github.com/SiirRandall/go-restful/internal/extract"    // Values taken from responses
+)
+
+// placeholder matches {{name}} with optional spaces around the name.
+var placeholder = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// 'Environment' is a named set of variables such as the base URL or credentials of one deployment.
+type Environment struct {
+	Name      string                `json:"name"`
+	Variables []collection.KeyValue `json:"variables"`
+	Path      string                `json:"-"` // File the environment is stored in
+}
+
+// Function 'Get' returns the value of a variable and whether it is defined.
+func (e *Environment) Get(name string) (string, bool) {
+	for _, variable := range e.Variables {
+		if variable.Key == name {
+			return variable.Value, true
+		}
+	}
+	return "", false
+}
+
+// Function 'Set' sets a variable, adding it when it does not exist yet.
+func (e *Environment) Set(name, value string) {
+	for i, variable := range e.Variables {
+		if variable.Key == name {
+			e.Variables[i].Value = value
+			return
+		}
+	}
+	e.Variables = append(e.Variables, collection.KeyValue{Key: name, Value: value})
+}
+
+// Function 'SetExtracted' sets the variable of every extraction that found a value, and reports
+// whether any variable changed.
+func (e *Environment) SetExtracted(results []extract.Result) bool {
+	changed := false
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+		if value, ok := e.Get(result.Rule.Variable); !ok || value != result.Value {
+			e.Set(result.Rule.Variable, result.Value)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Function 'Substitute' replaces every {{name}} in s with the value of the variable.
+// Unknown variables are left untouched so they are easy to spot. A nil environment changes nothing.
+func (e *Environment) Substitute(s string) string {
+	if e == nil || !strings.Contains(s, "{{") {
+		return s
+	}
+	return placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		name := placeholder.FindStringSubmatch(match)[1]
+		if value, ok := e.Get(name); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Function 'Apply' returns a copy of the request with variables substituted in the URL, params,
+// path params, headers, body, token and the targets and expected values of the assertions.
+func (e *Environment) Apply(r *collection.Request) *collection.Request {
+	resolved := r.Clone()
+	if e == nil {
+		return resolved
+	}
+
+	resolved.URL = e.Substitute(resolved.URL)
+	e.substituteAll(resolved.Params)
+	e.substituteAll(resolved.Path)
+	e.substituteAll(resolved.Headers)
+	resolved.Body.Raw = e.Substitute(resolved.Body.Raw)
+	resolved.Body.ContentType = e.Substitute(resolved.Body.ContentType)
+	resolved.Body.File = e.Substitute(resolved.Body.File)
+	e.substituteAll(resolved.Body.Form)
+	resolved.Auth = resolved.Auth.Map(e.Substitute)
+	for i := range resolved.Assertions {
+		resolved.Assertions[i].Target = e.Substitute(resolved.Assertions[i].Target)
+		resolved.Assertions[i].Expected = e.Substitute(resolved.Assertions[i].Expected)
+	}
+	return resolved
+}
+
+// Function 'substituteAll' substitutes variables in the keys and values of a list in place.
+func (e *Environment) substituteAll(pairs []collection.KeyValue) {
+	for i := range pairs {
+		pairs[i].Key = e.Substitute(pairs[i].Key)
+		pairs[i].Value = e.Substitute(pairs[i].Value)
+	}
+}
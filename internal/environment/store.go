@@ -0,0 +1,117 @@
+package environment // Package 'environment' stores named sets of variables and substitutes them into requests
+
+import (
+	"encoding/json" // For the file format
+	"fmt"           // For wrapping errors
+	"os"            // For reading and writing environment files
+	"path/filepath" // For building file names
+	"sort"          // For listing environments in a stable order
+	"strings"       // For file name checks
+
+	"github.com/SiirRandall/go-restful/internal/config" // Config directory lookup
+)
+
+// Function 'Dir' returns the directory environments are stored in, one JSON file each.
+func Dir() (string, error) {
+	return config.SubDir("environments")
+}
+
+// Function 'New' creates an empty environment that will be saved as name.json.
+func New(name string) (*Environment, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Environment{Name: name, Path: filepath.Join(dir, fileName(name)+".json")}, nil
+}
+
+// Function 'LoadAll' loads every environment in the environments directory, sorted by name.
+func LoadAll() ([]*Environment, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var environments []*Environment
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		e, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return environments, err
+		}
+		environments = append(environments, e)
+	}
+
+	sort.Slice(environments, func(i, j int) bool {
+		return strings.ToLower(environments[i].Name) < strings.ToLower(environments[j].Name)
+	})
+	return environments, nil
+}
+
+// Function 'Load' reads an environment file.
+func Load(path string) (*Environment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &Environment{Path: path}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("parsing environment %s: %w", path, err)
+	}
+	if e.Name == "" {
+		e.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return e, nil
+}
+
+// Function 'Find' loads an environment by the path of its file, or by name from the environments
+// directory, ignoring case.
+func Find(nameOrPath string) (*Environment, error) {
+	if info, err := os.Stat(nameOrPath); err == nil && !info.IsDir() {
+		return Load(nameOrPath)
+	}
+	environments, err := LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range environments {
+		if strings.EqualFold(e.Name, nameOrPath) {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("no environment named %q", nameOrPath)
+}
+
+// Function 'Save' writes the environment to its file.
+func (e *Environment) Save() error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(e.Path, data, 0o600)
+}
+
+// Function 'Delete' removes the environment file.
+func (e *Environment) Delete() error {
+	return os.Remove(e.Path)
+}
+
+// Function 'fileName' turns an environment name into a safe file name.
+func fileName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "environment"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, name)
+}
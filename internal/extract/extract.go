@@ -0,0 +1,186 @@
+package extract // Package 'extract' picks values out of a response into variables, so later requests can use them
+
+import (
+	"encoding/json" // For showing JSON values
+	"fmt"           // For errors
+	"net/http"      // For parsing Set-Cookie headers
+	"regexp"        // For the regex source
+	"strings"       // For parsing rules
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses values are taken from
+	"github.com/SiirRandall/go-restful/internal/jsonpath"              // Values picked out of JSON bodies
+)
+
+// Sources of a value, the part of the response it is taken from.
+const (
+	SourceJSON   = "json"   // Value a JSONPath or jq expression picks from a JSON body
+	SourceHeader = "header" // A response header, named by the expression
+	SourceRegex  = "regex"  // First group, or the whole match, of a regular expression on the body
+	SourceCookie = "cookie" // A cookie set by the response, named by the expression
+)
+
+// 'Rule' saves a value of the response into a variable, such as the token of a login response.
+type Rule struct {
+	Variable   string `json:"variable"           yaml:"variable"`
+	Source     string `json:"source"             yaml:"source"`             // One of the Source constants
+	Expression string `json:"expression"         yaml:"expression"`         // JSONPath, header or cookie name, or regular expression
+	Disabled   bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"` // Kept with the request but not applied
+}
+
+// 'Result' is the value a rule took from a response.
+type Result struct {
+	Rule  Rule
+	Value string
+	Error string // Why no value was found, the variable is then left as it was
+}
+
+// Function 'IsRule' reports whether a line is written as a rule rather than a check: set name = ...
+func IsRule(line string) bool {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+	return strings.EqualFold(keyword, "set")
+}
+
+// Function 'Parse' reads a rule written as a line:
+//
+//	set token = $.access_token      (json $.access_token, or jq: .access_token)
+//	set etag = header ETag
+//	set id = regex id=(\d+)
+//	set greeting = regex "Hello, (\w+)"   (quoted to keep spaces at either end)
+//	set session = cookie SESSIONID
+func Parse(line string) (Rule, error) {
+	var r Rule
+	if !IsRule(line) {
+		return r, fmt.Errorf("a variable is set with: set name = source expression")
+	}
+	_, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	variable, rest, ok := strings.Cut(rest, "=")
+	r.Variable = strings.TrimSpace(variable)
+	if !ok || r.Variable == "" || strings.ContainsAny(r.Variable, " {}") {
+		return r, fmt.Errorf("missing variable name, write: set name = source expression")
+	}
+
+	rest = strings.TrimSpace(rest)
+	switch {
+	case strings.HasPrefix(rest, "$") || strings.HasPrefix(rest, "."):
+		r.Source, r.Expression = SourceJSON, rest
+	default:
+		source, expression, _ := strings.Cut(rest, " ")
+		r.Source, r.Expression = strings.ToLower(source), strings.TrimSpace(expression)
+	}
+	if len(r.Expression) >= 2 && r.Expression[0] == '"' && r.Expression[len(r.Expression)-1] == '"' {
+		r.Expression = r.Expression[1 : len(r.Expression)-1] // Quotes keep spaces, backslashes are left for the regex
+	}
+	if r.Expression == "" {
+		return r, fmt.Errorf("missing what to take after %q", r.Source)
+	}
+
+	switch r.Source {
+	case SourceJSON:
+		if _, err := jsonpath.Compile(r.Expression); err != nil {
+			return r, err
+		}
+	case SourceRegex:
+		if _, err := regexp.Compile(r.Expression); err != nil {
+			return r, err
+		}
+	case SourceHeader, SourceCookie:
+	default:
+		return r, fmt.Errorf("unknown source %q, use json, header, regex or cookie", r.Source)
+	}
+	return r, nil
+}
+
+// Function 'String' writes the rule as a line Parse reads back.
+func (r Rule) String() string {
+	expression := r.Expression
+	if strings.TrimSpace(expression) != expression || strings.HasPrefix(expression, `"`) {
+		expression = `"` + expression + `"`
+	}
+	if r.Source == SourceJSON && (strings.HasPrefix(expression, "$") || strings.HasPrefix(expression, ".")) {
+		return fmt.Sprintf("set %s = %s", r.Variable, expression)
+	}
+	return fmt.Sprintf("set %s = %s %s", r.Variable, r.Source, expression)
+}
+
+// Function 'Apply' takes the value of every enabled rule from a response, in order.
+func Apply(rules []Rule, response httpclient.HttpResponseDetails) []Result {
+	var results []Result
+	for _, r := range rules {
+		if r.Disabled {
+			continue
+		}
+		value, err := r.Extract(response)
+		result := Result{Rule: r, Value: value}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// Function 'Extract' takes the value of the rule from a response.
+func (r Rule) Extract(response httpclient.HttpResponseDetails) (string, error) {
+	if response.Error != nil {
+		return "", fmt.Errorf("no response")
+	}
+	switch r.Source {
+	case SourceJSON:
+		if response.JsonData == nil {
+			return "", fmt.Errorf("the body is not JSON")
+		}
+		expression, err := jsonpath.Compile(r.Expression)
+		if err != nil {
+			return "", err
+		}
+		values := expression.Evaluate(response.JsonData)
+		if len(values) == 0 {
+			return "", fmt.Errorf("%s not found", r.Expression)
+		}
+		if expression.Definite() {
+			return valueText(values[0]), nil
+		}
+		return valueText(values), nil
+	case SourceHeader:
+		values := response.HeaderValues(r.Expression)
+		if len(values) == 0 {
+			return "", fmt.Errorf("no %s header", r.Expression)
+		}
+		return strings.Join(values, ", "), nil
+	case SourceRegex:
+		re, err := regexp.Compile(r.Expression)
+		if err != nil {
+			return "", err
+		}
+		match := re.FindSubmatch(response.Body)
+		switch {
+		case match == nil:
+			return "", fmt.Errorf("%s does not match the body", r.Expression)
+		case len(match) > 1:
+			return string(match[1]), nil
+		default:
+			return string(match[0]), nil
+		}
+	case SourceCookie:
+		header := http.Header{"Set-Cookie": response.HeaderValues("Set-Cookie")}
+		for _, cookie := range (&http.Response{Header: header}).Cookies() {
+			if cookie.Name == r.Expression {
+				return cookie.Value, nil
+			}
+		}
+		return "", fmt.Errorf("no %s cookie", r.Expression)
+	}
+	return "", fmt.Errorf("unknown source %q", r.Source)
+}
+
+// Function 'valueText' shows a JSON value: strings as they are, anything else as JSON.
+func valueText(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,106 @@
+package history // Package 'history' keeps a log of every request sent and the response it got
+
+import (
+	"time" // For entry timestamps and durations
+
+	"github.com/SiirRandall/go-restful/internal/auth"                  // For telling which headers hold secrets
+	"github.com/SiirRandall/go-restful/internal/collection"            // Saved requests module
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+	"github.com/SiirRandall/go-restful/internal/jsonvalue"             // For decoding JSON bodies when an entry is replayed
+)
+
+// MaxBodySize is the number of response body bytes kept per entry, longer bodies are truncated.
+const MaxBodySize = 512 * 1024
+
+// 'Entry' is a single request/response pair.
+type Entry struct {
+	Time        time.Time           `json:"time"`                  // When the request was sent
+	Environment string              `json:"environment,omitempty"` // Active environment, variables are kept unresolved in Request
+	Request     *collection.Request `json:"request"`               // Request as it was in the editor
+	Response    Response            `json:"response"`              // What came back
+}
+
+// 'Response' is the part of a response worth keeping in the history.
+type Response struct {
+	StatusCode int                 `json:"status,omitempty"`
+	StatusText string              `json:"statusText,omitempty"`
+	Protocol   string              `json:"protocol,omitempty"`
+	Headers    []httpclient.Header `json:"headers,omitempty"`
+	Body       []byte              `json:"body,omitempty"`
+	Size       int                 `json:"size"`                // Size of the full body, even when Body was truncated
+	WireSize   int                 `json:"wireSize,omitempty"`  // Size of the body as received, before decompression
+	Encoding   string              `json:"encoding,omitempty"`  // Content-Encoding the body was decompressed from
+	Charset    string              `json:"charset,omitempty"`   // Charset the body was converted to UTF-8 from
+	Truncated  bool                `json:"truncated,omitempty"` // Body holds only the first MaxBodySize bytes
+	Duration   httpclient.Duration `json:"duration"`
+	Error      string              `json:"error,omitempty"` // Set when no response was received
+}
+
+// Function 'NewEntry' records a request and its response. The secrets of the request's auth are left
+// out, and so are the values of the headers carrying credentials, such as Authorization or Set-Cookie.
+func NewEntry(request *collection.Request, environment string, response httpclient.HttpResponseDetails) *Entry {
+	entry := &Entry{
+		Time:        time.Now(),
+		Environment: environment,
+		Request:     request.Clone(),
+		Response: Response{
+			StatusCode: response.StatusCode,
+			StatusText: response.StatusText,
+			Protocol:   response.Protocol,
+			Headers:    RedactHeaders(response.Headers),
+			Body:       response.Body,
+			Size:       response.Size,
+			WireSize:   response.WireSize,
+			Encoding:   response.Encoding,
+			Charset:    response.Charset,
+			Duration:   httpclient.Duration(response.Timing.Total),
+		},
+	}
+	entry.Request.Auth = entry.Request.Auth.Upgrade().Redacted() // Secrets stay out of the history file, legacy tokens included
+	for i, header := range entry.Request.Headers {
+		if auth.SecretHeader(header.Key) {
+			entry.Request.Headers[i].Value = ""
+		}
+	}
+	if len(entry.Response.Body) > MaxBodySize {
+		entry.Response.Body = entry.Response.Body[:MaxBodySize]
+		entry.Response.Truncated = true
+	}
+	if response.Error != nil {
+		entry.Response.Error = response.Error.Error()
+	}
+	return entry
+}
+
+// Function 'RedactHeaders' returns a copy of response headers with the values of those carrying
+// credentials emptied, as they are stored in the history.
+func RedactHeaders(headers []httpclient.Header) []httpclient.Header {
+	redacted := append([]httpclient.Header(nil), headers...)
+	for i, header := range redacted {
+		if auth.SecretHeader(header.Key) {
+			redacted[i].Value = ""
+		}
+	}
+	return redacted
+}
+
+// Function 'HttpResponseDetails' turns the stored response back into response details so it can be shown again.
+func (r Response) HttpResponseDetails() httpclient.HttpResponseDetails {
+	details := httpclient.HttpResponseDetails{
+		StatusCode:    r.StatusCode,
+		StatusText:    r.StatusText,
+		Protocol:      r.Protocol,
+		Headers:       r.Headers,
+		ContentLength: -2,
+		Size:          r.Size,
+		WireSize:      r.WireSize,
+		Encoding:      r.Encoding,
+		Charset:       r.Charset,
+		Timing:        httpclient.Timing{Total: time.Duration(r.Duration)},
+		Body:          r.Body,
+	}
+	if jsonData, err := jsonvalue.Decode(r.Body); err == nil {
+		details.JsonData = jsonData
+	}
+	return details
+}
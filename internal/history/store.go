@@ -0,0 +1,208 @@
+package history // Package 'history' keeps a log of every request sent and the response it got
+
+import (
+	"bufio"         // For reading the log line by line
+	"bytes"         // For rewriting the trimmed log
+	"encoding/json" // For the file format, one JSON entry per line
+	"errors"        // For detecting a missing log
+	"io/fs"         // For the not-exist error
+	"os"            // For reading and writing the log
+	"path/filepath" // For building the file name
+	"sync"          // For serializing writes from concurrent requests
+
+	"github.com/SiirRandall/go-restful/internal/config" // Config directory lookup
+)
+
+// FileName is the name of the history log inside the config directory.
+const FileName = "history.jsonl"
+
+// Limits of the history log. Once either is exceeded the oldest entries are dropped.
+var (
+	MaxEntries       = 500              // Number of entries kept
+	MaxBytes   int64 = 20 * 1024 * 1024 // Size of the log file
+)
+
+var (
+	mu         sync.Mutex // Serializes writers, requests finish on their own goroutines
+	entryCount = -1       // Number of entries in the log, -1 until the log was read once
+)
+
+// Function 'Path' returns the location of the history log.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Function 'Append' adds an entry to the end of the log and trims the log when it grew too large.
+func Append(entry *Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if entryCount < 0 {
+		entries, err := readEntries(path)
+		if err != nil {
+			return err
+		}
+		entryCount = len(entries)
+	} else {
+		entryCount++
+	}
+	return trim(path)
+}
+
+// Function 'Load' reads every entry of the log, newest first. Lines that cannot be parsed are skipped.
+func Load() ([]*Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+	entryCount = len(entries)
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// Function 'Delete' removes a single entry, identified by its timestamp, from the log.
+func Delete(entry *Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	entries, err := readEntries(path)
+	if err != nil {
+		return err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if !e.Time.Equal(entry.Time) {
+			kept = append(kept, e)
+		}
+	}
+	return writeEntries(path, kept)
+}
+
+// Function 'Clear' empties the log.
+func Clear() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	entryCount = 0
+	return nil
+}
+
+// Function 'readEntries' reads the log oldest first. A missing log has no entries.
+func readEntries(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, int(MaxBytes)) // A single entry can be far longer than the default line limit
+	for scanner.Scan() {
+		entry := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil || entry.Request == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Function 'writeEntries' replaces the log with entries, oldest first.
+func writeEntries(path string, entries []*Entry) error {
+	entryCount = len(entries)
+	b := &bytes.Buffer{}
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+
+	// Write a temporary file first so a crash never leaves half a log behind
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Function 'trim' drops the oldest entries once the log holds more than MaxEntries or MaxBytes.
+func trim(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if entryCount <= MaxEntries && info.Size() <= MaxBytes {
+		return nil
+	}
+	entries, err := readEntries(path)
+	if err != nil {
+		return err
+	}
+
+	// Keep the newest entries that fit, leaving some room so the log is not rewritten on every request
+	var size int64
+	first := len(entries)
+	for first > 0 && len(entries)-first < MaxEntries*9/10 {
+		line, err := json.Marshal(entries[first-1])
+		if err != nil {
+			return err
+		}
+		if size+int64(len(line))+1 > MaxBytes*3/4 {
+			break
+		}
+		size += int64(len(line)) + 1
+		first--
+	}
+	return writeEntries(path, entries[first:])
+}
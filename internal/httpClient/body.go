@@ -0,0 +1,148 @@
+package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
+
+import (
+	"bytes"          // For building multipart bodies
+	"fmt"            // For formatted errors
+	"mime"           // For guessing the type of uploaded files
+	"mime/multipart" // For encoding multipart/form-data bodies
+	"net/textproto"  // For the headers of multipart file parts
+	"net/url"        // For encoding form bodies
+	"os"             // For reading uploaded files
+	"path/filepath"  // For the file name sent with a file part
+	"strings"        // For case-insensitive header lookups
+)
+
+// Body modes, how the fields of Body are turned into the request body.
+const (
+	BodyNone       = "none"       // No body
+	BodyRaw        = "raw"        // Raw text, sent as it is
+	BodyURLEncoded = "urlencoded" // Fields sent as application/x-www-form-urlencoded
+	BodyMultipart  = "multipart"  // Text and file fields sent as multipart/form-data
+	BodyBinary     = "binary"     // The content of a file
+)
+
+// BodyModes lists the body modes in the order they are offered.
+var BodyModes = []string{BodyNone, BodyRaw, BodyURLEncoded, BodyMultipart, BodyBinary}
+
+// 'FormField' is a field of a urlencoded or multipart body.
+type FormField struct {
+	Key   string
+	Value string // Text of the field, or the path of the file for file fields
+	File  bool   // Multipart only: send the content of the file at Value
+}
+
+// 'Body' describes a request body before it is encoded.
+type Body struct {
+	Mode        string      // One of the Body modes
+	ContentType string      // Raw mode: Content-Type sent unless a header sets one
+	Raw         string      // Raw mode: the body
+	Fields      []FormField // Urlencoded and multipart modes: the fields
+	File        string      // Binary mode: path of the file sent as the body
+}
+
+// Function 'EncodeBody' encodes Body into RequestBody and sets the Content-Type header that goes with
+// it; a Content-Type header that is already set wins, except that a multipart body always gets the
+// boundary it was encoded with. An empty body gets no Content-Type. Body is nil afterwards, so
+// encoding twice is harmless.
+func (d *HttpRequestDetails) EncodeBody() error {
+	if d.Body == nil {
+		return nil
+	}
+	body := d.Body
+	contentType := ""
+	switch body.Mode {
+	case BodyNone, "":
+		d.RequestBody = ""
+	case BodyRaw:
+		d.RequestBody = body.Raw
+		contentType = body.ContentType
+	case BodyURLEncoded:
+		values := url.Values{}
+		for _, field := range body.Fields {
+			values.Add(field.Key, field.Value)
+		}
+		d.RequestBody = values.Encode()
+		contentType = "application/x-www-form-urlencoded"
+	case BodyMultipart:
+		encoded, boundaryType, err := encodeMultipart(body.Fields)
+		if err != nil {
+			return err
+		}
+		d.RequestBody = encoded
+		d.Headers = withHeader(d.Headers, "Content-Type", boundaryType) // The boundary is only known now
+	case BodyBinary:
+		if body.File == "" {
+			return fmt.Errorf("no file selected for the binary body")
+		}
+		data, err := os.ReadFile(body.File)
+		if err != nil {
+			return fmt.Errorf("reading body file: %w", err)
+		}
+		d.RequestBody = string(data)
+		contentType = fileContentType(body.File)
+	default:
+		return fmt.Errorf("unknown body mode %q", body.Mode)
+	}
+
+	if contentType != "" && d.RequestBody != "" && !hasHeader(d.Headers, "Content-Type") {
+		d.Headers = withHeader(d.Headers, "Content-Type", contentType)
+	}
+	d.Body = nil
+	return nil
+}
+
+// Function 'hasHeader' reports whether a header is set, ignoring the case of its name.
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Function 'encodeMultipart' encodes form fields as multipart/form-data and returns the body and
+// the Content-Type including the boundary.
+func encodeMultipart(fields []FormField) (string, string, error) {
+	b := &bytes.Buffer{}
+	writer := multipart.NewWriter(b)
+	for _, field := range fields {
+		if !field.File {
+			if err := writer.WriteField(field.Key, field.Value); err != nil {
+				return "", "", err
+			}
+			continue
+		}
+
+		data, err := os.ReadFile(field.Value)
+		if err != nil {
+			return "", "", fmt.Errorf("reading form file %s: %w", field.Key, err)
+		}
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(field.Key), escapeQuotes(filepath.Base(field.Value))))
+		header.Set("Content-Type", fileContentType(field.Value))
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return "", "", err
+		}
+		part.Write(data)
+	}
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+	return b.String(), writer.FormDataContentType(), nil
+}
+
+// Function 'fileContentType' guesses the type of a file from its extension.
+func fileContentType(path string) string {
+	if fileType := mime.TypeByExtension(filepath.Ext(path)); fileType != "" {
+		return fileType
+	}
+	return "application/octet-stream"
+}
+
+// Function 'escapeQuotes' escapes a value for use inside a quoted Content-Disposition parameter.
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
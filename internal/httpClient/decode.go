@@ -0,0 +1,83 @@
+package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
+
+import (
+	"bytes"   // For looking for a charset in the body
+	"fmt"     // For formatted errors
+	"mime"    // For the charset parameter of the Content-Type
+	"regexp"  // For the charset of HTML and XML documents
+	"strings" // For splitting the Content-Encoding
+
+	"github.com/valyala/fasthttp"          // For the decompression helpers of the response
+	"golang.org/x/text/encoding/htmlindex" // For looking up charsets by the names browsers accept
+)
+
+// AcceptEncoding is sent when the request does not set Accept-Encoding itself, it lists every
+// Content-Encoding the body can be decompressed from.
+const AcceptEncoding = "gzip, deflate, br"
+
+// documentCharset finds the charset declared by an HTML <meta> tag or an XML declaration.
+var documentCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?([\w:.-]+)|<\?xml[^>]+encoding\s*=\s*["']([\w:.-]+)`)
+
+// Function 'decompressBody' undoes the Content-Encoding of a response, the encoding applied last
+// first, and returns the body with the encodings that were undone. When an encoding is unknown or the
+// body does not decompress, the body is returned as it was received along with the error.
+func decompressBody(resp *fasthttp.Response) ([]byte, string, error) {
+	wire := resp.Body()
+	header := strings.TrimSpace(string(resp.Header.Peek(fasthttp.HeaderContentEncoding)))
+	if header == "" || len(wire) == 0 {
+		return wire, "", nil
+	}
+
+	encodings := strings.Split(header, ",")
+	body := wire
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+		case "gzip", "x-gzip":
+			body, err = resp.BodyGunzip()
+		case "br":
+			body, err = resp.BodyUnbrotli()
+		case "deflate":
+			body, err = resp.BodyInflate()
+		case "identity", "":
+			continue
+		default:
+			return wire, "", fmt.Errorf("unsupported Content-Encoding %q", encoding)
+		}
+		if err != nil {
+			return wire, "", fmt.Errorf("could not decompress the %s body: %v", strings.TrimSpace(encodings[i]), err)
+		}
+		resp.SetBodyRaw(body) // The helpers read the body of the response, the next one gets this result
+	}
+	return body, header, nil
+}
+
+// Function 'transcodeBody' converts a body to UTF-8 from the charset of its Content-Type, or for
+// HTML and XML without one, from the charset the document declares. It returns the body with the
+// name of the charset it was converted from, "" when it needed no converting.
+func transcodeBody(body []byte, contentType string) ([]byte, string, error) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	label := params["charset"]
+	if label == "" && (strings.Contains(mediaType, "html") || strings.Contains(mediaType, "xml")) {
+		if m := documentCharset.FindSubmatch(body[:min(len(body), 1024)]); m != nil {
+			label = string(bytes.Join(m[1:], nil))
+		}
+	}
+	if label == "" {
+		return body, "", nil
+	}
+
+	encoding, err := htmlindex.Get(label)
+	if err != nil {
+		return body, "", fmt.Errorf("unknown charset %q", label)
+	}
+	name, _ := htmlindex.Name(encoding)
+	if name == "utf-8" {
+		return body, "", nil
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, "", fmt.Errorf("could not convert the body from %s: %v", name, err)
+	}
+	return decoded, name, nil
+}
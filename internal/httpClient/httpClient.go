@@ -1,10 +1,18 @@
 package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
 
 import (
-	"encoding/json" // For encoding and decoding JSON data
-	"fmt"           // For formatted I/O operations
+	"context"    // For cancelling a request that is in flight
+	"crypto/tls" // For the TLS configuration used when dialing HTTPS hosts
+	"fmt"        // For formatted I/O operations
+	"net"        // For the connection type returned by the dialer
+	"net/http"   // For the cookie jar interface
+	"net/url"    // For the proxy address
+	"strings"    // For case-insensitive header lookups
+	"time"       // For measuring the total request time
 
 	"github.com/valyala/fasthttp" // Importing the third-party package 'fasthttp' for handling HTTP client operations
+
+	"github.com/SiirRandall/go-restful/internal/jsonvalue" // For decoding JSON bodies in order
 )
 
 // 'HttpRequestDetails' is a struct that encapsulates all information required for an HTTP request.
@@ -13,43 +21,317 @@ type HttpRequestDetails struct {
 	Method      string            // HTTP request method (GET, POST etc.)
 	Headers     map[string]string // HTTP headers
 	RequestBody string            // Body of the HTTP request; used in POST requests
+	Body        *Body             // Body to encode into RequestBody when the request is sent, nil to send RequestBody as it is
+
+	Settings ClientSettings // Timeouts, redirects, TLS and proxy used to send the request
+
+	// Jar, when set, stores the cookies every response sets, redirects included, and adds the ones
+	// matching each request to its Cookie header
+	Jar http.CookieJar
+
+	// Challenge, when set, answers a 401 response: it gets the method, the request URI and the
+	// WWW-Authenticate values, and returns the Authorization header for a single retry
+	Challenge func(method, uri string, challenges []string) (string, error)
+
+	// Digest, when set, holds the Digest auth credentials Challenge answers with, so the request
+	// can be exported to tools that answer the challenge themselves
+	Digest *Credentials
+}
+
+// 'Credentials' is a user name and password.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// 'Header' is a single response header. Repeated headers (e.g. Set-Cookie) appear once per value.
+type Header struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 // 'HttpResponseDetails' is a struct that holds the details of an HTTP response.
 type HttpResponseDetails struct {
-	Body     []byte      // Raw response body
-	JsonData interface{} // Decoded JSON response data
-	Error    error       // Error (if any) while making the HTTP request or parsing the response
+	StatusCode    int         // Numeric HTTP status code (200, 404 etc.)
+	StatusText    string      // Reason phrase for the status code
+	Protocol      string      // Protocol reported by the server (e.g. HTTP/1.1)
+	RemoteAddr    string      // Address of the server that answered
+	Headers       []Header    // All response headers in the order they were received
+	ContentLength int         // Value of the Content-Length header (-1 if chunked, -2 if identity)
+	Size          int         // Number of body bytes, once decompressed
+	WireSize      int         // Number of body bytes received, before decompression
+	Encoding      string      // Content-Encoding the body was decompressed from, e.g. gzip
+	Charset       string      // Charset the body was converted to UTF-8 from, e.g. shift_jis
+	DecodeError   error       // Why the body could not be decompressed or converted, it is then kept as received
+	Timing        Timing      // Breakdown of how long the request took
+	URL           string      // URL that answered, differs from the request URL after redirects
+	Redirects     []string    // URLs that answered with a redirect, in the order they were followed
+	Body          []byte      // Response body, decompressed and converted to UTF-8
+	JsonData      interface{} // Decoded JSON response data, as returned by jsonvalue.Decode
+	Error         error       // Error (if any) while making the HTTP request or parsing the response
+}
+
+// Function 'HeaderValues' returns every value of the named response header, ignoring case.
+func (r HttpResponseDetails) HeaderValues(name string) []string {
+	var values []string
+	for _, header := range r.Headers {
+		if strings.EqualFold(header.Key, name) {
+			values = append(values, header.Value)
+		}
+	}
+	return values
 }
 
 // Function 'SendHttpRequest' takes in an object of HttpRequestDetails,
 // makes the HTTP request using fasthttp library and returns the response as HttpResponseDetails object.
 func SendHttpRequest(details HttpRequestDetails) HttpResponseDetails {
+	return SendHttpRequestContext(context.Background(), details)
+}
+
+// Function 'SendHttpRequestContext' is SendHttpRequest with a context. Cancelling ctx aborts the
+// request, whatever phase it is in, and the response then carries an error wrapping context.Canceled.
+// Redirects are followed here rather than by fasthttp so every hop gets its own timed connection.
+// A structured Body is encoded first, together with its Content-Type.
+func SendHttpRequestContext(ctx context.Context, details HttpRequestDetails) HttpResponseDetails {
+	if err := details.EncodeBody(); err != nil {
+		return HttpResponseDetails{Error: fmt.Errorf(" Error in request body: %v", err)}
+	}
+	settings := details.Settings
+	tlsConfig, err := settings.tlsConfig()
+	if err != nil {
+		return HttpResponseDetails{Error: fmt.Errorf(" Error in TLS settings: %v", err)}
+	}
+	proxy, err := settings.proxyURL()
+	if err != nil {
+		return HttpResponseDetails{Error: fmt.Errorf(" Error in proxy settings: %v", err)}
+	}
+
+	start := time.Now()
+	var redirects []string
+	challenged := false
+	for {
+		response, location := sendOnce(ctx, details, tlsConfig, proxy)
+		if response.Error == nil {
+			storeCookies(details.Jar, details.URL, response.HeaderValues("Set-Cookie"))
+		}
+		response.Redirects = redirects
+		response.Timing.Total = time.Since(start)
+		if response.Error == nil && response.StatusCode == fasthttp.StatusUnauthorized && details.Challenge != nil && !challenged {
+			challenged = true
+			authorization, err := details.Challenge(details.Method, requestURI(details.URL), response.HeaderValues(fasthttp.HeaderWWWAuthenticate))
+			if err != nil {
+				response.Error = fmt.Errorf(" Error answering the authentication challenge: %v", err)
+				return response
+			}
+			details.Headers = withHeader(details.Headers, "Authorization", authorization)
+			continue
+		}
+		if response.Error != nil || location == "" || !settings.FollowRedirects {
+			return response
+		}
+		if len(redirects) >= settings.maxRedirects() {
+			response.Error = fmt.Errorf(" Stopped after %d redirects", len(redirects))
+			return response
+		}
+
+		redirects = append(redirects, details.URL)
+		if !sameHost(details.URL, location) {
+			details.Headers = withoutCredentials(details.Headers) // Do not leak credentials to another host
+			details.Challenge = nil
+		}
+		details.URL = location
+		details.Method, details.RequestBody = redirectMethod(details.Method, details.RequestBody, response.StatusCode)
+	}
+}
+
+// Function 'redirectMethod' returns the method and body to use after a redirect with the given status.
+// 303 always switches to GET, 301 and 302 do so for POST as browsers do, 307 and 308 keep everything.
+func redirectMethod(method, body string, status int) (string, string) {
+	switch {
+	case status == fasthttp.StatusSeeOther && method != fasthttp.MethodHead:
+		return fasthttp.MethodGet, ""
+	case (status == fasthttp.StatusMovedPermanently || status == fasthttp.StatusFound) && method == fasthttp.MethodPost:
+		return fasthttp.MethodGet, ""
+	}
+	return method, body
+}
+
+// Function 'sameHost' reports whether two absolute URLs point at the same host and port.
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// Function 'withoutCredentials' returns a copy of headers without Authorization and Cookie.
+func withoutCredentials(headers map[string]string) map[string]string {
+	kept := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if strings.EqualFold(key, "Authorization") || strings.EqualFold(key, "Cookie") {
+			continue
+		}
+		kept[key] = value
+	}
+	return kept
+}
+
+// Function 'withHeader' returns a copy of headers with name set to value, replacing it whatever its case.
+func withHeader(headers map[string]string, name, value string) map[string]string {
+	updated := make(map[string]string, len(headers)+1)
+	for key, existing := range headers {
+		if !strings.EqualFold(key, name) {
+			updated[key] = existing
+		}
+	}
+	updated[name] = value
+	return updated
+}
+
+// Function 'storeCookies' hands the Set-Cookie values of a response from rawURL to the jar.
+func storeCookies(jar http.CookieJar, rawURL string, setCookies []string) {
+	if jar == nil || len(setCookies) == 0 {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	header := http.Header{"Set-Cookie": setCookies}
+	jar.SetCookies(u, (&http.Response{Header: header}).Cookies())
+}
+
+// Function 'cookieHeader' returns the Cookie header for a request to rawURL: the cookies of the jar
+// matching it, after those the request sets itself, which win over jar cookies of the same name.
+func cookieHeader(jar http.CookieJar, rawURL string, own []byte) string {
+	if jar == nil {
+		return string(own)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return string(own)
+	}
+	pairs := []string{}
+	if len(own) > 0 {
+		pairs = append(pairs, string(own))
+	}
+	ownNames := make(map[string]bool)
+	for _, cookie := range (&http.Request{Header: http.Header{"Cookie": {string(own)}}}).Cookies() {
+		ownNames[cookie.Name] = true
+	}
+	for _, cookie := range jar.Cookies(u) {
+		if !ownNames[cookie.Name] {
+			pairs = append(pairs, cookie.String())
+		}
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// Function 'requestURI' returns the path and query of an absolute URL, as sent in the request line.
+func requestURI(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.RequestURI()
+}
+
+// Function 'sendOnce' sends a single request without following redirects. Besides the response it
+// returns the absolute URL of the Location header when the response is a redirect.
+func sendOnce(ctx context.Context, details HttpRequestDetails, tlsConfig *tls.Config, proxy *url.URL) (HttpResponseDetails, string) {
 	req := fasthttp.AcquireRequest()     // Acquires an HTTP request instance
 	resp := fasthttp.AcquireResponse()   // Acquires an HTTP response instance
 	defer fasthttp.ReleaseRequest(req)   // Make sure to release request instance after it's no longer needed
 	defer fasthttp.ReleaseResponse(resp) // Same goes for the response instance
 
-	req.SetRequestURI(details.URL)         // Sets the request URL
-	req.Header.SetMethod(details.Method)   // Sets the request method (GET, POST, etc.)
-	req.SetBodyString(details.RequestBody) // Sets the request body
+	req.SetRequestURI(details.URL)       // Sets the request URL
+	req.Header.SetMethod(details.Method) // Sets the request method (GET, POST, etc.)
+	if MethodAllowsBody(details.Method) {
+		req.SetBodyString(details.RequestBody) // Sets the request body
+	}
+	resp.SkipBody = !ResponseHasBody(details.Method) // Do not wait for a body the server will never send
 
 	for key, value := range details.Headers { // Iterating through each header and setting it on the request
 		req.Header.Set(key, value)
 	}
+	if cookie := cookieHeader(details.Jar, details.URL, req.Header.Peek(fasthttp.HeaderCookie)); cookie != "" {
+		req.Header.DelAllCookies() // fasthttp keeps parsed cookies, setting the header again would add to them
+		req.Header.Set(fasthttp.HeaderCookie, cookie)
+	}
+	if !hasHeader(details.Headers, fasthttp.HeaderAcceptEncoding) {
+		req.Header.Set(fasthttp.HeaderAcceptEncoding, AcceptEncoding) // Compressed bodies are decompressed below
+	}
+
+	// A fresh client per request means every call dials a new connection, so the timing is always complete
+	settings := details.Settings
+	recorder := &timingRecorder{start: time.Now()}
+	isTLS := string(req.URI().Scheme()) == "https"
+	client := &fasthttp.Client{
+		Dial: func(addr string) (net.Conn, error) {
+			return recorder.dial(ctx, addr, isTLS, settings, tlsConfig, proxy)
+		},
+		ReadTimeout:  time.Duration(settings.ReadTimeout),
+		WriteTimeout: time.Duration(settings.WriteTimeout),
+
+		MaxIdemponentCallAttempts: 1, // Send exactly once, a retry would hide timeouts and skew the timing
+	}
+	defer client.CloseIdleConnections()
 
-	err := fasthttp.Do(req, resp) // Executes the request and stores the response
+	// fasthttp knows nothing about contexts, closing the connection is what makes Do return early
+	stop := context.AfterFunc(ctx, recorder.abort)
+	defer stop()
+
+	err := client.Do(req, resp) // Executes the request and stores the response
+	timing, remoteAddr := recorder.result()
+	if ctx.Err() != nil {
+		return HttpResponseDetails{Timing: timing, Error: fmt.Errorf(" Request cancelled: %w", ctx.Err())}, ""
+	}
 	if err != nil {
-		return HttpResponseDetails{Error: fmt.Errorf(" Error making request: %v", err)} // If there was an error, return it
+		return HttpResponseDetails{Timing: timing, Error: fmt.Errorf(" Error making request: %v", err)}, "" // If there was an error, return it
+	}
+
+	wireSize := len(resp.Body())
+	body, encoding, decodeErr := decompressBody(resp)
+	body = append([]byte(nil), body...) // Copy the response body, resp is released when we return
+	charset := ""
+	if decodeErr == nil {
+		body, charset, decodeErr = transcodeBody(body, string(resp.Header.ContentType()))
 	}
 
-	body := resp.Body() // Get the response body
+	response := HttpResponseDetails{
+		StatusCode:    resp.StatusCode(),
+		StatusText:    fasthttp.StatusMessage(resp.StatusCode()),
+		Protocol:      string(resp.Header.Protocol()),
+		RemoteAddr:    remoteAddr,
+		ContentLength: resp.Header.ContentLength(),
+		Size:          len(body),
+		WireSize:      wireSize,
+		Encoding:      encoding,
+		Charset:       charset,
+		DecodeError:   decodeErr,
+		Timing:        timing,
+		URL:           details.URL,
+		Body:          body,
+	}
+
+	// Copy every header, including repeated ones, in the order the server sent them
+	resp.Header.VisitAll(func(key, value []byte) {
+		response.Headers = append(response.Headers, Header{Key: string(key), Value: string(value)})
+	})
+
+	// Resolve the Location of a redirect against the request URL
+	location := ""
+	if fasthttp.StatusCodeIsRedirect(response.StatusCode) {
+		if value := resp.Header.Peek(fasthttp.HeaderLocation); len(value) > 0 {
+			req.URI().Update(string(value))
+			location = req.URI().String()
+		}
+	}
 
-	var jsonData interface{}
-	err = json.Unmarshal(body, &jsonData) // Try to unmarshal the response body into JSON format
+	jsonData, err := jsonvalue.Decode(body) // Try to decode the response body as JSON, keeping member order and number text
 	if err != nil {
-		return HttpResponseDetails{Body: body, Error: nil} // If unable to unmarshal, return the raw body
+		return response, location // If unable to unmarshal, return the raw body
 	}
 
-	return HttpResponseDetails{Body: body, JsonData: jsonData, Error: nil} // Return the response body, unmarshalled JSON data and no error
+	response.JsonData = jsonData
+	return response, location // Return the response with the unmarshalled JSON data and no error
 }
@@ -0,0 +1,51 @@
+package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
+
+import (
+	"strings" // For method name checks
+)
+
+// StandardMethods lists the methods defined by the HTTP specifications, most used first.
+var StandardMethods = []string{
+	"GET",
+	"POST",
+	"PUT",
+	"PATCH",
+	"DELETE",
+	"HEAD",
+	"OPTIONS",
+	"TRACE",
+	"CONNECT",
+}
+
+// Function 'ValidMethod' reports whether method is a valid HTTP method token (RFC 9110), so custom
+// verbs such as PROPFIND, REPORT or PURGE are accepted but names with spaces or separators are not.
+func ValidMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+	for _, r := range method {
+		isAlnum := ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+		if !isAlnum && !strings.ContainsRune("!#$%&'*+-.^_`|~", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Function 'MethodAllowsBody' reports whether a request body may be sent with the method.
+// A TRACE request must not carry content, so its body is dropped.
+func MethodAllowsBody(method string) bool {
+	return !strings.EqualFold(method, "TRACE")
+}
+
+// Function 'ResponseHasBody' reports whether the body of a response to the method should be read.
+// Responses to HEAD never have one, and a successful CONNECT turns the connection into a tunnel,
+// so reading a body for either would wait for data that never comes.
+func ResponseHasBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case "HEAD", "CONNECT":
+		return false
+	default:
+		return true
+	}
+}
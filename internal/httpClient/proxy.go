@@ -0,0 +1,146 @@
+package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
+
+import (
+	"bufio"           // For reading the proxy's CONNECT response
+	"encoding/base64" // For Proxy-Authorization credentials
+	"encoding/binary" // For the SOCKS5 port field
+	"errors"          // For proxy errors
+	"fmt"             // For formatted errors and the CONNECT request
+	"io"              // For reading SOCKS5 replies
+	"net"             // For the tunnelled connection
+	"net/http"        // For parsing the CONNECT response
+	"net/url"         // For the proxy URL and its credentials
+	"strconv"         // For the SOCKS5 port field
+)
+
+// Function 'tunnel' asks the proxy behind conn to connect to addr ("host:port"). Once it returns
+// without error, conn talks to addr directly, so TLS and HTTP can be run over it as usual.
+func tunnel(conn net.Conn, proxy *url.URL, addr string) error {
+	if proxy.Scheme == "http" {
+		return httpConnect(conn, proxy, addr)
+	}
+	return socks5Connect(conn, proxy, addr)
+}
+
+// Function 'httpConnect' opens a tunnel through an HTTP proxy with the CONNECT method.
+func httpConnect(conn net.Conn, proxy *url.URL, addr string) error {
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		request += "Proxy-Authorization: Basic " + credentials + "\r\n"
+	}
+	if _, err := io.WriteString(conn, request+"\r\n"); err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+
+	// Read byte by byte so nothing that belongs to the tunnel is swallowed by the buffer
+	response, err := http.ReadResponse(bufio.NewReaderSize(byteReader{conn}, 16), nil)
+	if err != nil {
+		return fmt.Errorf("proxy: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy refused the connection: %s", response.Status)
+	}
+	return nil
+}
+
+// 'byteReader' reads one byte at a time from a connection.
+type byteReader struct {
+	conn net.Conn
+}
+
+// Function 'Read' reads at most one byte.
+func (r byteReader) Read(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	return r.conn.Read(b[:1])
+}
+
+// Function 'socks5Connect' opens a tunnel through a SOCKS5 proxy (RFC 1928), with optional
+// username/password authentication (RFC 1929). The host name is resolved by the proxy.
+func socks5Connect(conn net.Conn, proxy *url.URL, addr string) error {
+	host, portText, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return err
+	}
+	if len(host) > 255 {
+		return errors.New("socks5: host name too long")
+	}
+
+	// Greeting: offer no authentication, and username/password when credentials are set
+	methods := []byte{0x00}
+	if proxy.User != nil {
+		methods = []byte{0x02}
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	if reply[0] != 0x05 || reply[1] != methods[0] {
+		return errors.New("socks5: proxy rejected the authentication methods")
+	}
+
+	if proxy.User != nil {
+		username := proxy.User.Username()
+		password, _ := proxy.User.Password()
+		auth := []byte{0x01, byte(len(username))}
+		auth = append(auth, username...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := conn.Write(auth); err != nil {
+			return fmt.Errorf("socks5: %w", err)
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return fmt.Errorf("socks5: %w", err)
+		}
+		if reply[1] != 0x00 {
+			return errors.New("socks5: authentication failed")
+		}
+	}
+
+	// CONNECT request with the destination as a domain name
+	request := []byte{0x05, 0x01, 0x00, 0x03, byte(len(host))}
+	request = append(request, host...)
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+
+	// Reply: version, status, reserved, address type, bound address and port
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	if header[1] != 0x00 {
+		return fmt.Errorf("socks5: proxy could not connect (code %d)", header[1])
+	}
+	var skip int
+	switch header[3] {
+	case 0x01:
+		skip = net.IPv4len
+	case 0x04:
+		skip = net.IPv6len
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("socks5: %w", err)
+		}
+		skip = int(length[0])
+	default:
+		return errors.New("socks5: malformed reply")
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return fmt.Errorf("socks5: %w", err)
+	}
+	return nil
+}
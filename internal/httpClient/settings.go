@@ -0,0 +1,224 @@
+package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
+
+import (
+	"crypto/tls"  // For the TLS configuration built from the settings
+	"crypto/x509" // For custom CA bundles
+	"errors"      // For settings validation errors
+	"fmt"         // For wrapping errors
+	"net/url"     // For parsing the proxy URL
+	"os"          // For reading the CA bundle
+	"time"        // For timeouts
+)
+
+// DefaultMaxRedirects is the number of hops followed when redirects are enabled without a limit.
+const DefaultMaxRedirects = 10
+
+// 'Duration' is a time.Duration stored as text ("5s", "1m30s") in settings files.
+type Duration time.Duration
+
+// Function 'MarshalText' writes the duration in Go duration syntax.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Function 'UnmarshalText' reads a duration in Go duration syntax. A bare number is taken as seconds.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Function 'ParseDuration' parses "1.5s", "200ms" or a bare number of seconds. An empty string is zero.
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	d, err := time.ParseDuration(s + "s")
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// 'ClientSettings' controls how a request is sent: timeouts, redirects, TLS and proxy.
+// Zero values mean no timeout, no redirects, system TLS only and a direct connection.
+// Timeouts and flags are always written out so a saved zero or false is not mistaken for a missing default.
+type ClientSettings struct {
+	ConnectTimeout Duration `json:"connectTimeout" yaml:"connectTimeout"` // DNS, TCP connect, proxy and TLS handshake
+	ReadTimeout    Duration `json:"readTimeout"    yaml:"readTimeout"`    // Reading the response
+	WriteTimeout   Duration `json:"writeTimeout"   yaml:"writeTimeout"`   // Writing the request
+
+	FollowRedirects bool `json:"followRedirects" yaml:"followRedirects"` // Follow 3xx responses
+	MaxRedirects    int  `json:"maxRedirects"    yaml:"maxRedirects"`    // Hop limit, DefaultMaxRedirects when zero
+
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"       yaml:"insecureSkipVerify"`       // Accept any certificate
+	CACertFile         string `json:"caCertFile,omitempty"     yaml:"caCertFile,omitempty"`     // PEM bundle trusted on top of the system roots
+	ClientCertFile     string `json:"clientCertFile,omitempty" yaml:"clientCertFile,omitempty"` // PEM client certificate for mTLS
+	ClientKeyFile      string `json:"clientKeyFile,omitempty"  yaml:"clientKeyFile,omitempty"`  // PEM key of the client certificate
+
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"` // http://[user:pass@]host:port or socks5://[user:pass@]host:port
+}
+
+// 'SettingsOverride' replaces single fields of the global settings for one request.
+// Nil fields keep the global value.
+type SettingsOverride struct {
+	ConnectTimeout     *Duration `json:"connectTimeout,omitempty"     yaml:"connectTimeout,omitempty"`
+	ReadTimeout        *Duration `json:"readTimeout,omitempty"        yaml:"readTimeout,omitempty"`
+	WriteTimeout       *Duration `json:"writeTimeout,omitempty"       yaml:"writeTimeout,omitempty"`
+	FollowRedirects    *bool     `json:"followRedirects,omitempty"    yaml:"followRedirects,omitempty"`
+	MaxRedirects       *int      `json:"maxRedirects,omitempty"       yaml:"maxRedirects,omitempty"`
+	InsecureSkipVerify *bool     `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	CACertFile         *string   `json:"caCertFile,omitempty"         yaml:"caCertFile,omitempty"`
+	ClientCertFile     *string   `json:"clientCertFile,omitempty"     yaml:"clientCertFile,omitempty"`
+	ClientKeyFile      *string   `json:"clientKeyFile,omitempty"      yaml:"clientKeyFile,omitempty"`
+	Proxy              *string   `json:"proxy,omitempty"              yaml:"proxy,omitempty"`
+}
+
+// Function 'Apply' returns the settings with every field set in o replaced. A nil override changes nothing.
+func (s ClientSettings) Apply(o *SettingsOverride) ClientSettings {
+	if o == nil {
+		return s
+	}
+	if o.ConnectTimeout != nil {
+		s.ConnectTimeout = *o.ConnectTimeout
+	}
+	if o.ReadTimeout != nil {
+		s.ReadTimeout = *o.ReadTimeout
+	}
+	if o.WriteTimeout != nil {
+		s.WriteTimeout = *o.WriteTimeout
+	}
+	if o.FollowRedirects != nil {
+		s.FollowRedirects = *o.FollowRedirects
+	}
+	if o.MaxRedirects != nil {
+		s.MaxRedirects = *o.MaxRedirects
+	}
+	if o.InsecureSkipVerify != nil {
+		s.InsecureSkipVerify = *o.InsecureSkipVerify
+	}
+	if o.CACertFile != nil {
+		s.CACertFile = *o.CACertFile
+	}
+	if o.ClientCertFile != nil {
+		s.ClientCertFile = *o.ClientCertFile
+	}
+	if o.ClientKeyFile != nil {
+		s.ClientKeyFile = *o.ClientKeyFile
+	}
+	if o.Proxy != nil {
+		s.Proxy = *o.Proxy
+	}
+	return s
+}
+
+// Function 'IsEmpty' reports whether the override leaves every setting alone.
+func (o *SettingsOverride) IsEmpty() bool {
+	return o == nil || *o == SettingsOverride{}
+}
+
+// Function 'Clone' returns a deep copy of the override.
+func (o *SettingsOverride) Clone() *SettingsOverride {
+	if o == nil {
+		return nil
+	}
+	clone := &SettingsOverride{}
+	clone.ConnectTimeout = clonePointer(o.ConnectTimeout)
+	clone.ReadTimeout = clonePointer(o.ReadTimeout)
+	clone.WriteTimeout = clonePointer(o.WriteTimeout)
+	clone.FollowRedirects = clonePointer(o.FollowRedirects)
+	clone.MaxRedirects = clonePointer(o.MaxRedirects)
+	clone.InsecureSkipVerify = clonePointer(o.InsecureSkipVerify)
+	clone.CACertFile = clonePointer(o.CACertFile)
+	clone.ClientCertFile = clonePointer(o.ClientCertFile)
+	clone.ClientKeyFile = clonePointer(o.ClientKeyFile)
+	clone.Proxy = clonePointer(o.Proxy)
+	return clone
+}
+
+// Function 'clonePointer' copies the value behind p into a new pointer.
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// Function 'maxRedirects' returns the hop limit to use when redirects are followed.
+func (s ClientSettings) maxRedirects() int {
+	if s.MaxRedirects <= 0 {
+		return DefaultMaxRedirects
+	}
+	return s.MaxRedirects
+}
+
+// Function 'proxyURL' parses the proxy setting. It returns nil when no proxy is configured.
+func (s ClientSettings) proxyURL() (*url.URL, error) {
+	if s.Proxy == "" {
+		return nil, nil
+	}
+	raw := s.Proxy
+	if !containsScheme(raw) {
+		raw = "http://" + raw // A bare host:port is an HTTP proxy
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, use http or socks5", u.Scheme)
+	}
+	if u.Port() == "" {
+		return nil, errors.New("proxy address needs a port")
+	}
+	return u, nil
+}
+
+// Function 'containsScheme' reports whether s starts with "scheme://".
+func containsScheme(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// Function 'tlsConfig' builds the TLS configuration: certificate checks, extra CA roots and the client certificate.
+func (s ClientSettings) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: s.InsecureSkipVerify}
+
+	if s.CACertFile != "" {
+		pem, err := os.ReadFile(s.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", s.CACertFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if s.ClientCertFile != "" || s.ClientKeyFile != "" {
+		keyFile := s.ClientKeyFile
+		if keyFile == "" {
+			keyFile = s.ClientCertFile // Certificate and key may share one PEM file
+		}
+		cert, err := tls.LoadX509KeyPair(s.ClientCertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,174 @@
+package httpclient // Package 'httpclient' provides utilities for handling HTTP requests and responses
+
+import (
+	"context"    // For the DNS resolver and cancelling a dial
+	"crypto/tls" // For performing the TLS handshake ourselves so it can be timed
+	"net"        // For DNS lookups and raw TCP connections
+	"net/url"    // For the proxy address
+	"sync"       // For guarding the timing data shared with the connection
+	"time"       // For measuring the phases of a request
+)
+
+// 'Timing' is a breakdown of how long each phase of an HTTP request took.
+type Timing struct {
+	DNS       time.Duration // Time spent resolving the host name
+	Connect   time.Duration // Time spent establishing the TCP connection
+	TLS       time.Duration // Time spent in the TLS handshake (zero for plain HTTP)
+	FirstByte time.Duration // Time from the start of the request until the first response byte arrived
+	Total     time.Duration // Time for the whole request, including reading the body
+}
+
+// 'timingRecorder' collects timing information and the remote address while fasthttp dials and reads.
+type timingRecorder struct {
+	mu         sync.Mutex
+	start      time.Time
+	timing     Timing
+	remoteAddr string
+	firstByte  bool
+	conn       net.Conn // Connection dialed for the request, closed to abort it
+	cancelled  bool     // Set once the request was cancelled, later dials are closed right away
+}
+
+// Function 'dial' resolves, connects and (for HTTPS) handshakes to addr, recording how long every step took.
+// With a proxy, the proxy is resolved and connected to and a tunnel to addr is opened through it.
+// The returned connection is already a TLS connection when isTLS is set, which fasthttp accepts as-is.
+// Dialing stops as soon as ctx is done, or once the connect timeout (if any) has passed.
+func (t *timingRecorder) dial(
+	ctx context.Context,
+	addr string,
+	isTLS bool,
+	settings ClientSettings,
+	tlsConfig *tls.Config,
+	proxy *url.URL,
+) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if settings.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(settings.ConnectTimeout))
+		defer cancel()
+	}
+
+	// With a proxy the TCP connection goes to the proxy, the target is reached through the tunnel
+	dialHost, dialPort := host, port
+	if proxy != nil {
+		dialHost, dialPort = proxy.Hostname(), proxy.Port()
+	}
+
+	// Resolve the host name first so the DNS phase can be measured on its own
+	dnsStart := time.Now()
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, dialHost)
+	if err != nil {
+		return nil, err
+	}
+	t.record(func() { t.timing.DNS = time.Since(dnsStart) })
+
+	// Connect to the first address that accepts the connection
+	connectStart := time.Now()
+	var conn net.Conn
+	dialer := &net.Dialer{}
+	for _, ip := range ips {
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), dialPort))
+		if err == nil {
+			break
+		}
+	}
+	if conn == nil {
+		return nil, err
+	}
+	cancelled := false
+	t.record(func() {
+		t.conn = conn // From now on a cancel closes the connection
+		cancelled = t.cancelled
+	})
+	if cancelled {
+		conn.Close()
+		return nil, context.Canceled
+	}
+	if proxy != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		if err := tunnel(conn, proxy, addr); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.SetDeadline(time.Time{})
+	}
+	t.record(func() {
+		t.timing.Connect = time.Since(connectStart) // Includes opening the proxy tunnel
+		t.remoteAddr = conn.RemoteAddr().String()
+	})
+
+	if isTLS {
+		config := tlsConfig.Clone()
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+		tlsStart := time.Now()
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		t.record(func() { t.timing.TLS = time.Since(tlsStart) })
+		conn = tlsConn
+	}
+
+	return &timingConn{Conn: conn, recorder: t}, nil
+}
+
+// Function 'record' runs f while holding the recorder lock.
+func (t *timingRecorder) record(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f()
+}
+
+// Function 'abort' closes the connection of the request so fasthttp returns from a pending read or write.
+func (t *timingRecorder) abort() {
+	t.record(func() {
+		t.cancelled = true
+		if t.conn != nil {
+			t.conn.Close()
+		}
+	})
+}
+
+// Function 'result' returns a copy of the timing collected so far.
+func (t *timingRecorder) result() (Timing, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.timing, t.remoteAddr
+}
+
+// 'timingConn' wraps a connection to note the moment the first response byte is read.
+type timingConn struct {
+	net.Conn
+	recorder *timingRecorder
+}
+
+// Function 'Read' reads from the wrapped connection and records the time to first byte.
+func (c *timingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.recorder.record(func() {
+			if !c.recorder.firstByte {
+				c.recorder.firstByte = true
+				c.recorder.timing.FirstByte = time.Since(c.recorder.start)
+			}
+		})
+	}
+	return n, err
+}
+
+// Function 'Handshake' lets fasthttp know the connection is already a TLS connection, it would
+// otherwise wrap it in a second TLS client. The handshake itself was done (and timed) by dial.
+func (c *timingConn) Handshake() error {
+	if tlsConn, ok := c.Conn.(*tls.Conn); ok {
+		return tlsConn.Handshake()
+	}
+	return nil
+}
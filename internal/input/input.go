@@ -9,13 +9,10 @@ import (
 	"github.com/SiirRandall/go-restful/internal/tui" // Internal import of tui
 )
 
-// Global boolean variable that keeps track if a logView is visible or not
-var isVisible = false
-
 // TextViewMouseCapture handels mouse captures on the TextView
 func TextViewMouseCapture(
 	logView *tview.TextView, // The logView where events will be logged to
-	textView *tui.ScrollTextView, // The textView on which the click event happened
+	textView *tui.ResponseViewer, // The textView on which the click event happened
 	headersForm *tview.Form, // The form which contains header fields
 ) {
 	textView.SetMouseCapture( // Sets a handler which gets executed when a mouse event occurs on the textView
@@ -53,11 +50,10 @@ func TextViewMouseCapture(
 // TextViewKBCapture handels input captures (keypresses) on the TextView
 func TextViewKBCapture(
 	app *tview.Application, // TUI application instance
-	textView *tui.ScrollTextView, // The textView on which the keypress event occurred
-	logView *tview.TextView, // The logView to log events
-	detailsForm *tview.Form, // The form which contains detail fields
+	forms *tui.RequestForms, // The forms of the request, the textView is their response viewer
 	grid *tview.Flex, // The grid layout container
 ) {
+	textView, logView := forms.Viewer, forms.Log
 	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey { // Sets a handler function that gets executed whenever a key press occurs.
 		// Log the captured key to logView
 		tui.LogMessage(
@@ -69,26 +65,28 @@ func TextViewKBCapture(
 			),
 		)
 
+		// While a search or path is typed every key belongs to the prompt
+		if textView.Prompting() {
+			return event
+		}
+
 		if event.Rune() == 'l' { // If "l" was pressed
-			if isVisible {
+			if logShown(grid, logView) {
 				grid.RemoveItem(logView) // Remove logView from grid
 			} else {
 				grid.AddItem(logView, 12, 1, false) // Add logView to grid
 			}
-			isVisible = !isVisible // Toggle isVisible flag
 			return event
 		}
 		// Check if Tab is pressed
 		if event.Key() == tcell.KeyTab {
 			// If Shift modifier is present
 			if event.Modifiers() == tcell.ModShift {
-				// Set focus on Fetch JSON button
-				item := detailsForm.GetFormItem(1)
-				app.SetFocus(item)
+				// Set focus on the Send button
+				app.SetFocus(forms.Buttons)
 			} else {
 				// Set focus on URL input
-				item := detailsForm.GetFormItem(0)
-				app.SetFocus(item)
+				app.SetFocus(forms.URL)
 			}
 			return nil // Event fully handled, no further processing needed
 		}
@@ -97,22 +95,42 @@ func TextViewKBCapture(
 	})
 }
 
+// logShown reports whether the logView is one of the items of the grid
+func logShown(grid *tview.Flex, logView *tview.TextView) bool {
+	for i := 0; i < grid.GetItemCount(); i++ {
+		if grid.GetItem(i) == logView {
+			return true
+		}
+	}
+	return false
+}
+
 // UrlInputCapture handels input captures (keypresses) on the UrlInputField
 func UrlInputCapture(
-	logView *tview.TextView, // The logView to log events
-	urlForm *tview.Form, // The form which contains the URL field
-	detailsForm *tview.Form, // The form which contains detail fields
-	textView *tui.ScrollTextView, // The textView to display logs
-	detailsView *tview.TextView, // The detailsView to display response details
-	methodbox *tview.Form, // The methodbox to select API request method
+	app *tview.Application, // TUI application instance, the response is drawn through it
+	forms *tui.RequestForms, // The forms which describe the request being edited
 ) {
-	urlField := urlForm.GetFormItem(0).(*tview.InputField)                 // Get the URL input field from urlForm
+	urlField := forms.URL.GetFormItem(0).(*tview.InputField)               // Get the URL input field from urlForm
 	urlField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey { // Sets a handler function that gets executed whenever a key press occurs.
 		if event.Key() == tcell.KeyEnter { // When Enter key is pressed...
-			tui.SendAction(urlForm, detailsForm, logView, textView, detailsView, methodbox) // Send the HTTP request using the entered data
-			_, method := methodbox.GetFormItem(0).(*tview.DropDown).GetCurrentOption()      // Get the current selected option from the drop-down in methodbox
-			tui.LogMessage(logView, fmt.Sprintf("Main Using method: %s", method))           // Log the method used
+			tui.SendAction(app, forms)                                                                    // Send the HTTP request using the entered data
+			tui.LogMessage(forms.Log, fmt.Sprintf("Main Using method: %s", forms.Session.Request.Method)) // Log the method used
 		}
 		return event // Return the unchanged event so it can continue being processed
 	})
 }
+
+// AppKBCapture registers application wide keyboard shortcuts. Shortcuts are checked before
+// the focused primitive sees the key, so they work from anywhere in the UI.
+func AppKBCapture(
+	app *tview.Application, // TUI application instance
+	shortcuts map[tcell.Key]func(), // Handlers keyed by the shortcut key (e.g. tcell.KeyCtrlS)
+) {
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if handler, ok := shortcuts[event.Key()]; ok {
+			handler()
+			return nil // Event fully handled, no further processing needed
+		}
+		return event
+	})
+}
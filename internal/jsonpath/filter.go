@@ -0,0 +1,245 @@
+package jsonpath // Package 'jsonpath' evaluates JSONPath expressions, and a jq compatible subset, against decoded JSON
+
+import (
+	"encoding/json" // For numbers decoded as json.Number
+	"reflect"       // For comparing objects and arrays
+	"strconv"       // For number literals
+	"strings"       // For matching keywords
+)
+
+// 'expr' is a node of a filter expression such as @.price < 10 && @.inStock.
+type expr interface{}
+
+// 'literalExpr' is a string, number, true, false or null.
+type literalExpr struct {
+	value interface{}
+}
+
+// 'pathExpr' is @.path, relative to the value being filtered, or $.path, from the document.
+type pathExpr struct {
+	fromRoot bool
+	segments []segment
+}
+
+// 'notExpr' is !operand.
+type notExpr struct {
+	operand expr
+}
+
+// 'logicalExpr' is left && right, or left || right.
+type logicalExpr struct {
+	and         bool
+	left, right expr
+}
+
+// 'compareExpr' is left op right.
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+// comparisonOperators are tried in order, so the two character operators win.
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// Function 'test' reports whether a filter expression holds for the current value. A path alone
+// tests that it selects something, a literal is tested for truthiness.
+func test(e expr, current, root interface{}) bool {
+	switch e := e.(type) {
+	case notExpr:
+		return !test(e.operand, current, root)
+	case logicalExpr:
+		if e.and {
+			return test(e.left, current, root) && test(e.right, current, root)
+		}
+		return test(e.left, current, root) || test(e.right, current, root)
+	case compareExpr:
+		return compare(e, current, root)
+	case pathExpr:
+		_, ok := evaluate(e, current, root)
+		return ok
+	case literalExpr:
+		return e.value != nil && e.value != false && e.value != ""
+	}
+	return false
+}
+
+// Function 'evaluate' returns the value of an operand, and false when a path selects nothing. A path
+// selecting several values gives the first.
+func evaluate(e expr, current, root interface{}) (interface{}, bool) {
+	switch e := e.(type) {
+	case literalExpr:
+		return e.value, true
+	case pathExpr:
+		start := current
+		if e.fromRoot {
+			start = root
+		}
+		values := apply(e.segments, []interface{}{start}, root)
+		if len(values) == 0 {
+			return nil, false
+		}
+		if number, ok := values[0].(json.Number); ok { // Compared with number literals
+			if f, err := number.Float64(); err == nil {
+				return f, true
+			}
+		}
+		return values[0], true
+	}
+	return test(e, current, root), true
+}
+
+// Function 'compare' evaluates a comparison. Ordering only applies to two numbers or two strings; a
+// missing value is only unequal to an existing one.
+func compare(e compareExpr, current, root interface{}) bool {
+	left, leftOK := evaluate(e.left, current, root)
+	right, rightOK := evaluate(e.right, current, root)
+	if !leftOK || !rightOK {
+		return e.op == "!=" && leftOK != rightOK
+	}
+
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+
+	var order int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case l < r:
+			order = -1
+		case l > r:
+			order = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false
+		}
+		order = strings.Compare(l, r)
+	default:
+		return false
+	}
+	switch e.op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	default: // ">="
+		return order >= 0
+	}
+}
+
+// Function 'parseOr' reads a || chain.
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpaces(); p.peek("||"); p.skipSpaces() {
+		p.pos += 2
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+// Function 'parseAnd' reads a && chain.
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.skipSpaces(); p.peek("&&"); p.skipSpaces() {
+		p.pos += 2
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// Function 'parseUnary' reads a negation, a parenthesized expression or a comparison.
+func (p *parser) parseUnary() (expr, error) {
+	p.skipSpaces()
+	switch {
+	case p.peek("!") && !p.peek("!="):
+		p.pos++
+		operand, err := p.parseUnary()
+		return notExpr{operand: operand}, err
+	case p.peek("("):
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.peek(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, op := range comparisonOperators {
+		if p.peek(op) {
+			p.pos += len(op)
+			right, err := p.parseOperand()
+			return compareExpr{op: op, left: left, right: right}, err
+		}
+	}
+	return left, nil
+}
+
+// Function 'parseOperand' reads a path, a string, a number, true, false or null.
+func (p *parser) parseOperand() (expr, error) {
+	p.skipSpaces()
+	switch {
+	case p.peek("@"), p.peek("$"):
+		fromRoot := p.peek("$")
+		p.pos++
+		segments, err := p.parsePath()
+		return pathExpr{fromRoot: fromRoot, segments: segments}, err
+	case p.peek("'"), p.peek(`"`):
+		s, err := p.parseString()
+		return literalExpr{value: s}, err
+	case p.peek("true"):
+		p.pos += len("true")
+		return literalExpr{value: true}, nil
+	case p.peek("false"):
+		p.pos += len("false")
+		return literalExpr{value: false}, nil
+	case p.peek("null"):
+		p.pos += len("null")
+		return literalExpr{value: nil}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) && strings.ContainsRune("0123456789.-+eE", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	number, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.errorf("expected a value")
+	}
+	return literalExpr{value: number}, nil
+}
@@ -0,0 +1,174 @@
+package jsonpath // Package 'jsonpath' evaluates JSONPath expressions, and a jq compatible subset, against decoded JSON
+
+import (
+	"fmt"     // For errors
+	"sort"    // For visiting object members in a stable order
+	"strings" // For splitting jq pipelines
+
+	"github.com/SiirRandall/go-restful/internal/jsonvalue" // Ordered objects
+)
+
+// 'Expression' is a compiled JSONPath expression or jq pipeline.
+type Expression struct {
+	stages   [][]segment // Paths applied one after the other, a jq pipeline has one per stage
+	definite bool        // Set when the expression can only select a single value
+}
+
+// Function 'Compile' parses an expression. JSONPath expressions start with '$' ($.data[*].id,
+// $..price, $.items[?(@.price < 10)]); jq expressions start with '.' and may be piped
+// (.data[].id, .data | .[0]). A bare name such as data.id is read as $.data.id.
+func Compile(expr string) (*Expression, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	var paths []string
+	switch {
+	case strings.HasPrefix(expr, "$"):
+		paths = []string{expr}
+	case strings.HasPrefix(expr, "."):
+		for _, stage := range splitPipeline(expr) {
+			stage = strings.TrimSpace(stage)
+			if !strings.HasPrefix(stage, ".") {
+				return nil, fmt.Errorf("jq stage %q must start with '.'", stage)
+			}
+			stage = strings.ReplaceAll(stage, "[]", "[*]")
+			if stage == "." {
+				stage = ""
+			} else if strings.HasPrefix(stage, ".[") {
+				stage = stage[1:]
+			}
+			paths = append(paths, "$"+stage)
+		}
+	default:
+		paths = []string{"$." + expr}
+	}
+
+	compiled := &Expression{definite: true}
+	for _, path := range paths {
+		p := &parser{src: path, pos: 1} // Skip the '$'
+		segments, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos < len(p.src) {
+			return nil, p.errorf("unexpected %q", p.src[p.pos:])
+		}
+		for _, seg := range segments {
+			compiled.definite = compiled.definite && seg.definite()
+		}
+		compiled.stages = append(compiled.stages, segments)
+	}
+	return compiled, nil
+}
+
+// Function 'Evaluate' returns every value of data selected by the expression, in document order. data
+// is decoded JSON, from jsonvalue.Decode or encoding/json.
+func (e *Expression) Evaluate(data interface{}) []interface{} {
+	values := []interface{}{data}
+	for _, stage := range e.stages {
+		values = apply(stage, values, data)
+	}
+	return values
+}
+
+// Function 'Definite' reports whether the expression selects at most one value: it only uses names
+// and indexes, without wildcards, slices, unions, filters or recursive descent.
+func (e *Expression) Definite() bool {
+	return e.definite
+}
+
+// Function 'Filter' compiles and evaluates an expression in one go. A definite expression returns the
+// value it selects, anything else returns the list of selected values.
+func Filter(data interface{}, expr string) (interface{}, error) {
+	compiled, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	values := compiled.Evaluate(data)
+	if compiled.Definite() {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("nothing at %s", expr)
+		}
+		return values[0], nil
+	}
+	if values == nil {
+		values = []interface{}{}
+	}
+	return values, nil
+}
+
+// Function 'splitPipeline' splits a jq expression on the '|' that are not inside brackets or quotes.
+func splitPipeline(expr string) []string {
+	var stages []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == '|' && depth == 0 && !strings.HasPrefix(expr[i:], "||"):
+			stages = append(stages, expr[start:i])
+			start = i + 1
+		case c == '|' && depth == 0:
+			i++ // '||' is an operator, not a pipe
+		}
+	}
+	return append(stages, expr[start:])
+}
+
+// Function 'apply' runs the segments of a path over a list of values. root is the document, for '$'
+// inside filters.
+func apply(segments []segment, values []interface{}, root interface{}) []interface{} {
+	for _, seg := range segments {
+		var next []interface{}
+		for _, value := range values {
+			next = seg.selectFrom(value, root, next)
+		}
+		values = next
+	}
+	return values
+}
+
+// Function 'members' returns the names of an object's members, sorted so results are stable.
+func members(object map[string]interface{}) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Function 'children' returns the member values or items of an object or array, nil for anything else.
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case jsonvalue.Object:
+		values := make([]interface{}, len(v))
+		for i, member := range v {
+			values[i] = member.Value
+		}
+		return values
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, name := range members(v) {
+			values = append(values, v[name])
+		}
+		return values
+	case []interface{}:
+		return v
+	}
+	return nil
+}
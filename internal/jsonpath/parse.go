@@ -0,0 +1,309 @@
+package jsonpath // Package 'jsonpath' evaluates JSONPath expressions, and a jq compatible subset, against decoded JSON
+
+import (
+	"fmt"     // For parse errors
+	"strconv" // For indexes and number literals
+	"strings" // For matching operators
+
+	"github.com/SiirRandall/go-restful/internal/jsonvalue" // Ordered objects
+)
+
+// Kinds of selectors inside a segment.
+const (
+	selectName     = iota // ['name'] or .name
+	selectIndex           // [3] or [-1]
+	selectWildcard        // [*] or .*
+	selectSlice           // [start:end:step]
+	selectFilter          // [?(@.price < 10)]
+)
+
+// 'segment' is one step of a path: .name, [..], or ..name for recursive descent.
+type segment struct {
+	recursive bool       // '..': the selectors apply to the value and to every value below it
+	selectors []selector // Results of all selectors are joined, in order
+}
+
+// 'selector' picks values out of an object or array.
+type selector struct {
+	kind       int
+	name       string
+	index      int
+	start, end *int // Slice bounds, nil when left out
+	step       int  // Slice step, 1 when left out
+	filter     expr
+}
+
+// 'parser' reads an expression from src, starting at pos.
+type parser struct {
+	src string
+	pos int
+}
+
+// Function 'definite' reports whether the segment selects at most one value.
+func (s segment) definite() bool {
+	return !s.recursive && len(s.selectors) == 1 &&
+		(s.selectors[0].kind == selectName || s.selectors[0].kind == selectIndex)
+}
+
+// Function 'selectFrom' appends to out the values the segment selects from value.
+func (s segment) selectFrom(value, root interface{}, out []interface{}) []interface{} {
+	if !s.recursive {
+		for _, sel := range s.selectors {
+			out = sel.selectFrom(value, root, out)
+		}
+		return out
+	}
+	for _, sel := range s.selectors {
+		out = sel.selectFrom(value, root, out)
+	}
+	for _, child := range children(value) {
+		out = s.selectFrom(child, root, out)
+	}
+	return out
+}
+
+// Function 'selectFrom' appends to out the values the selector picks from value.
+func (sel selector) selectFrom(value, root interface{}, out []interface{}) []interface{} {
+	switch sel.kind {
+	case selectName:
+		switch object := value.(type) {
+		case jsonvalue.Object:
+			if member, ok := object.Get(sel.name); ok {
+				out = append(out, member)
+			}
+		case map[string]interface{}:
+			if member, ok := object[sel.name]; ok {
+				out = append(out, member)
+			}
+		}
+	case selectIndex:
+		if array, ok := value.([]interface{}); ok {
+			index := sel.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				out = append(out, array[index])
+			}
+		}
+	case selectWildcard:
+		out = append(out, children(value)...)
+	case selectSlice:
+		if array, ok := value.([]interface{}); ok {
+			out = sel.slice(array, out)
+		}
+	case selectFilter:
+		for _, child := range children(value) {
+			if test(sel.filter, child, root) {
+				out = append(out, child)
+			}
+		}
+	}
+	return out
+}
+
+// Function 'slice' appends the items of a [start:end:step] slice, with Python semantics.
+func (sel selector) slice(array []interface{}, out []interface{}) []interface{} {
+	n := len(array)
+	bound := func(b *int, fallback int) int {
+		if b == nil {
+			return fallback
+		}
+		i := *b
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+	if sel.step > 0 {
+		for i := max(bound(sel.start, 0), 0); i < min(bound(sel.end, n), n); i += sel.step {
+			out = append(out, array[i])
+		}
+	} else if sel.step < 0 {
+		for i := min(bound(sel.start, n-1), n-1); i > max(bound(sel.end, -n-1), -1); i += sel.step {
+			out = append(out, array[i])
+		}
+	}
+	return out
+}
+
+// Function 'errorf' returns a parse error pointing at the current position.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at column %d", fmt.Sprintf(format, args...), p.pos+1)
+}
+
+// Function 'skipSpaces' moves past blanks.
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// Function 'peek' reports whether the rest of the source starts with s.
+func (p *parser) peek(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+// Function 'parsePath' reads segments until something that is not a segment.
+func (p *parser) parsePath() ([]segment, error) {
+	var segments []segment
+	for p.pos < len(p.src) {
+		var seg segment
+		switch {
+		case p.peek(".."):
+			p.pos += 2
+			seg.recursive = true
+		case p.peek("."):
+			p.pos++
+		case p.peek("["):
+		default:
+			return segments, nil
+		}
+
+		var err error
+		switch {
+		case p.peek("["):
+			seg.selectors, err = p.parseBracket()
+		case p.peek("*"):
+			p.pos++
+			seg.selectors = []selector{{kind: selectWildcard}}
+		default:
+			var name string
+			name, err = p.parseName()
+			seg.selectors = []selector{{kind: selectName, name: name}}
+		}
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// Function 'parseName' reads a member name written after a dot.
+func (p *parser) parseName() (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(".[]()|,=!<>&*'\" \t", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a name")
+	}
+	return p.src[start:p.pos], nil
+}
+
+// Function 'parseBracket' reads the comma separated selectors between '[' and ']'.
+func (p *parser) parseBracket() ([]selector, error) {
+	p.pos++ // '['
+	var selectors []selector
+	for {
+		p.skipSpaces()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+		p.skipSpaces()
+		switch {
+		case p.peek(","):
+			p.pos++
+		case p.peek("]"):
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+// Function 'parseSelector' reads one selector inside brackets.
+func (p *parser) parseSelector() (selector, error) {
+	switch {
+	case p.peek("*"):
+		p.pos++
+		return selector{kind: selectWildcard}, nil
+	case p.peek("'"), p.peek(`"`):
+		name, err := p.parseString()
+		return selector{kind: selectName, name: name}, err
+	case p.peek("?"):
+		p.pos++
+		filter, err := p.parseOr()
+		return selector{kind: selectFilter, filter: filter}, err
+	}
+
+	// An index or a slice
+	sel := selector{kind: selectIndex, step: 1}
+	var bounds [3]*int
+	part := 0
+	for {
+		p.skipSpaces()
+		if number, ok := p.parseInt(); ok {
+			bounds[part] = &number
+		}
+		p.skipSpaces()
+		if !p.peek(":") || part == 2 {
+			break
+		}
+		p.pos++
+		part++
+		sel.kind = selectSlice
+	}
+	if sel.kind == selectIndex {
+		if bounds[0] == nil {
+			return sel, p.errorf("expected a selector")
+		}
+		sel.index = *bounds[0]
+		return sel, nil
+	}
+	sel.start, sel.end = bounds[0], bounds[1]
+	if bounds[2] != nil {
+		sel.step = *bounds[2]
+	}
+	return sel, nil
+}
+
+// Function 'parseInt' reads an optionally negative integer, it reports false when there is none.
+func (p *parser) parseInt() (int, bool) {
+	start := p.pos
+	if p.peek("-") {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	number, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return number, true
+}
+
+// Function 'parseString' reads a single or double quoted string with backslash escapes.
+func (p *parser) parseString() (string, error) {
+	quote := p.src[p.pos]
+	p.pos++
+	b := &strings.Builder{}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\\' && p.pos < len(p.src):
+			escaped := p.src[p.pos]
+			p.pos++
+			switch escaped {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(escaped)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("missing closing quote")
+}
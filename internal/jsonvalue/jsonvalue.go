@@ -0,0 +1,108 @@
+package jsonvalue // Package 'jsonvalue' decodes JSON keeping the order of object members and the exact text of numbers
+
+import (
+	"bytes"         // For encoding objects
+	"encoding/json" // For the token stream and json.Number
+	"fmt"           // For errors
+	"io"            // For detecting the end of the input
+)
+
+// 'Member' is a name and value of an object.
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// 'Object' is a JSON object with its members in document order. Repeated names are kept.
+type Object []Member
+
+// Function 'Decode' decodes a JSON document. Objects become Object, arrays []interface{}, numbers
+// json.Number (the exact text received), strings string, booleans bool and null nil.
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// Function 'decodeValue' reads the next value from the token stream.
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil // A scalar: json.Number, string, bool or nil
+	}
+
+	switch delim {
+	case '{':
+		object := Object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, Member{Key: key.(string), Value: value})
+		}
+		_, err = decoder.Token() // '}'
+		return object, err
+	case '[':
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token() // ']'
+		return array, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// Function 'Get' returns the value of a member, the last one when the name is repeated.
+func (o Object) Get(key string) (interface{}, bool) {
+	for i := len(o) - 1; i >= 0; i-- {
+		if o[i].Key == key {
+			return o[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Function 'MarshalJSON' encodes the object with its members in order.
+func (o Object) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(member.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
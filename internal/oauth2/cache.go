@@ -0,0 +1,113 @@
+package oauth2 // Package 'oauth2' fetches, caches and refreshes OAuth 2.0 access tokens
+
+import (
+	"crypto/sha256" // For cache keys that do not reveal the configuration
+	"encoding/hex"  // For printable cache keys
+	"encoding/json" // For the cache file format
+	"errors"        // For detecting a missing cache file
+	"io/fs"         // For the not-exist error
+	"os"            // For reading and writing the cache file
+	"path/filepath" // For building the file name
+	"strings"       // For joining the key parts
+	"sync"          // For serializing access from concurrent requests
+
+	"github.com/SiirRandall/go-restful/internal/config" // Config directory lookup
+)
+
+// FileName is the name of the token cache inside the config directory.
+const FileName = "tokens.json"
+
+var (
+	mu     sync.Mutex       // Guards tokens and the cache file
+	tokens map[string]Token // Cached tokens by key, nil until the cache file was read
+)
+
+// Function 'Path' returns the location of the token cache.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Function 'Cached' returns the cached token for cfg, expired or not.
+func Cached(cfg Config) (Token, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadTokens(); err != nil {
+		return Token{}, false
+	}
+	token, ok := tokens[cacheKey(cfg)]
+	return token, ok
+}
+
+// Function 'Forget' drops the cached token for cfg, so the next request fetches a new one.
+func Forget(cfg Config) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadTokens(); err != nil {
+		return err
+	}
+	delete(tokens, cacheKey(cfg))
+	return saveTokens()
+}
+
+// Function 'store' caches the token for cfg and writes the cache file.
+func store(cfg Config, token Token) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := loadTokens(); err != nil {
+		tokens = make(map[string]Token) // Replace an unreadable cache rather than failing every request
+	}
+	tokens[cacheKey(cfg)] = token
+	return saveTokens()
+}
+
+// Function 'cacheKey' identifies the token of a configuration. Secrets are part of the key so
+// changing them fetches a new token, hashing keeps them out of the file.
+func cacheKey(cfg Config) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		cfg.Grant, cfg.TokenURL, cfg.AuthURL, cfg.ClientID, cfg.ClientSecret,
+		cfg.Scope, cfg.Username, cfg.Password, cfg.RefreshToken,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Function 'loadTokens' reads the cache file once. A missing file is an empty cache.
+func loadTokens() error {
+	if tokens != nil {
+		return nil
+	}
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		tokens = make(map[string]Token)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	loaded := make(map[string]Token)
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+	tokens = loaded
+	return nil
+}
+
+// Function 'saveTokens' writes the cache file, readable by the user only as it holds credentials.
+func saveTokens() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,196 @@
+package oauth2 // Package 'oauth2' fetches, caches and refreshes OAuth 2.0 access tokens
+
+import (
+	"context"         // For cancelling a token request
+	"encoding/base64" // For client credentials sent with Basic auth
+	"encoding/json"   // For decoding token responses
+	"errors"          // For incomplete configurations
+	"fmt"             // For formatted errors
+	"net/url"         // For encoding token requests
+	"strings"         // For string manipulations
+	"time"            // For token expiry
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// Grant types, as sent in the grant_type parameter.
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password"
+	GrantRefreshToken      = "refresh_token"
+	GrantAuthorizationCode = "authorization_code"
+)
+
+// Grants lists the supported grant types in the order they are offered.
+var Grants = []string{GrantClientCredentials, GrantPassword, GrantRefreshToken, GrantAuthorizationCode}
+
+// ExpiryLeeway is how long before its expiry a token is already treated as expired, so it is not
+// refreshed in the middle of a request.
+var ExpiryLeeway = time.Minute
+
+// 'Config' describes how to get a token from an authorization server.
+type Config struct {
+	Grant        string // One of the Grant constants
+	TokenURL     string // Token endpoint
+	AuthURL      string // Authorization endpoint, authorization code grant only
+	ClientID     string
+	ClientSecret string // Empty for public clients
+	Scope        string // Space separated scopes, may be empty
+	Username     string // Password grant only
+	Password     string // Password grant only
+	RefreshToken string // Refresh token grant only
+	RedirectPort string // Loopback port of the authorization code redirect, empty picks a free one
+}
+
+// 'Token' is an access token as returned by the token endpoint.
+type Token struct {
+	AccessToken  string    `json:"accessToken"`
+	TokenType    string    `json:"tokenType,omitempty"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"` // Zero when the server did not say
+	Issued       time.Time `json:"issued,omitempty"` // When the token was requested
+}
+
+// Function 'Valid' reports whether the token can still be used for at least ExpiryLeeway, or half
+// its lifetime for tokens that live shorter than two leeways.
+func (t Token) Valid() bool {
+	if t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry.IsZero() {
+		return true
+	}
+	leeway := min(ExpiryLeeway, t.Expiry.Sub(t.Issued)/2)
+	return time.Until(t.Expiry) > leeway
+}
+
+// Function 'Authorization' returns the value of the Authorization header for the token.
+func (t Token) Authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer" // Servers often send "bearer", which some resource servers reject
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// Function 'Fetch' returns a valid token for cfg: the cached one, a refreshed one or a new one.
+// The authorization code grant calls authorize with the URL the user has to open in a browser.
+// Tokens are sent with the client settings, so the proxy and TLS settings apply to them too.
+func Fetch(ctx context.Context, cfg Config, settings httpclient.ClientSettings, authorize func(authURL string)) (Token, error) {
+	if token, ok := Cached(cfg); ok && token.Valid() {
+		return token, nil
+	}
+
+	var (
+		token Token
+		err   error
+	)
+	// An expired token with a refresh token is refreshed, a new token is requested when that fails
+	if cached, ok := Cached(cfg); ok && cached.RefreshToken != "" {
+		token, err = refresh(ctx, cfg, settings, cached.RefreshToken)
+		if err == nil && token.RefreshToken == "" {
+			token.RefreshToken = cached.RefreshToken // The server may keep the refresh token as it is
+		}
+	}
+	if token.AccessToken == "" && ctx.Err() == nil {
+		token, err = request(ctx, cfg, settings, authorize)
+	}
+	if err != nil {
+		return Token{}, err
+	}
+	return token, store(cfg, token)
+}
+
+// Function 'request' runs the configured grant.
+func request(ctx context.Context, cfg Config, settings httpclient.ClientSettings, authorize func(authURL string)) (Token, error) {
+	if cfg.TokenURL == "" {
+		return Token{}, errors.New("OAuth2 needs a token URL")
+	}
+	form := url.Values{}
+	switch cfg.Grant {
+	case GrantClientCredentials, "":
+		form.Set("grant_type", GrantClientCredentials)
+	case GrantPassword:
+		form.Set("grant_type", GrantPassword)
+		form.Set("username", cfg.Username)
+		form.Set("password", cfg.Password)
+	case GrantRefreshToken:
+		if cfg.RefreshToken == "" {
+			return Token{}, errors.New("the refresh token grant needs a refresh token")
+		}
+		return refresh(ctx, cfg, settings, cfg.RefreshToken)
+	case GrantAuthorizationCode:
+		return authorizationCode(ctx, cfg, settings, authorize)
+	default:
+		return Token{}, fmt.Errorf("unknown OAuth2 grant %q", cfg.Grant)
+	}
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+	return exchange(ctx, cfg, settings, form)
+}
+
+// Function 'refresh' trades a refresh token for a new access token.
+func refresh(ctx context.Context, cfg Config, settings httpclient.ClientSettings, refreshToken string) (Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", GrantRefreshToken)
+	form.Set("refresh_token", refreshToken)
+	if cfg.Scope != "" {
+		form.Set("scope", cfg.Scope)
+	}
+	return exchange(ctx, cfg, settings, form)
+}
+
+// Function 'exchange' posts form to the token endpoint and decodes the token it answers with.
+// Confidential clients authenticate with Basic auth, public clients send their id in the form.
+func exchange(ctx context.Context, cfg Config, settings httpclient.ClientSettings, form url.Values) (Token, error) {
+	headers := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"Accept":       "application/json",
+	}
+	if cfg.ClientSecret != "" {
+		credentials := url.QueryEscape(cfg.ClientID) + ":" + url.QueryEscape(cfg.ClientSecret)
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	} else if cfg.ClientID != "" {
+		form.Set("client_id", cfg.ClientID)
+	}
+
+	sent := time.Now()
+	response := httpclient.SendHttpRequestContext(ctx, httpclient.HttpRequestDetails{
+		URL:         cfg.TokenURL,
+		Method:      "POST",
+		Headers:     headers,
+		RequestBody: form.Encode(),
+		Settings:    settings,
+	})
+	if response.Error != nil {
+		return Token{}, fmt.Errorf("token request failed: %w", response.Error)
+	}
+
+	var body struct {
+		AccessToken      string      `json:"access_token"`
+		TokenType        string      `json:"token_type"`
+		RefreshToken     string      `json:"refresh_token"`
+		ExpiresIn        json.Number `json:"expires_in"` // Some servers send it as a string
+		Error            string      `json:"error"`
+		ErrorDescription string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(response.Body, &body); err != nil {
+		return Token{}, fmt.Errorf("token endpoint answered %d %s without a JSON token", response.StatusCode, response.StatusText)
+	}
+	if body.Error != "" {
+		if body.ErrorDescription != "" {
+			return Token{}, fmt.Errorf("token endpoint: %s: %s", body.Error, body.ErrorDescription)
+		}
+		return Token{}, fmt.Errorf("token endpoint: %s", body.Error)
+	}
+	if body.AccessToken == "" {
+		return Token{}, fmt.Errorf("token endpoint answered %d %s without an access token", response.StatusCode, response.StatusText)
+	}
+
+	token := Token{AccessToken: body.AccessToken, TokenType: body.TokenType, RefreshToken: body.RefreshToken, Issued: sent}
+	if seconds, err := body.ExpiresIn.Float64(); err == nil && seconds > 0 {
+		token.Expiry = sent.Add(time.Duration(seconds * float64(time.Second)))
+	}
+	return token, nil
+}
@@ -0,0 +1,482 @@
+package oauth2
+
+import (
+	"context"           // For the token requests
+	"crypto/sha256"     // For checking the PKCE code challenge
+	"encoding/base64"   // For checking the PKCE code challenge
+	"encoding/json"     // For the fake token responses
+	"net/http"          // For the fake token endpoint and following the redirect
+	"net/http/httptest" // For the fake token endpoint
+	"net/url"           // For reading the authorization URL
+	"os"                // For checking the cache file
+	"path/filepath"     // For the cache file location
+	"strings"           // For matching error messages
+	"sync"              // For guarding the recorded requests
+	"testing"           // Go test framework
+	"time"              // For token expiry
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// testSettings are the client settings the tokens are fetched with.
+var testSettings = httpclient.ClientSettings{
+	ConnectTimeout: httpclient.Duration(5 * time.Second),
+	ReadTimeout:    httpclient.Duration(5 * time.Second),
+	WriteTimeout:   httpclient.Duration(5 * time.Second),
+}
+
+// 'tokenEndpoint' is a fake token endpoint. It answers every request with the next of its
+// responses, repeating the last one, and records the forms it was sent.
+type tokenEndpoint struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses []map[string]any
+	forms     []url.Values
+	headers   []http.Header
+}
+
+// Function 'newTokenEndpoint' starts a fake token endpoint answering with responses.
+func newTokenEndpoint(t *testing.T, responses ...map[string]any) *tokenEndpoint {
+	t.Helper()
+	endpoint := &tokenEndpoint{responses: responses}
+	endpoint.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		endpoint.mu.Lock()
+		response := endpoint.responses[min(len(endpoint.forms), len(endpoint.responses)-1)]
+		endpoint.forms = append(endpoint.forms, r.PostForm)
+		endpoint.headers = append(endpoint.headers, r.Header)
+		endpoint.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, failed := response["error"]; failed {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(endpoint.Close)
+	return endpoint
+}
+
+// Function 'requests' returns the forms sent to the endpoint so far.
+func (e *tokenEndpoint) requests() []url.Values {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]url.Values(nil), e.forms...)
+}
+
+// Function 'useTempCache' points the token cache at an empty directory for the test.
+func useTempCache(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GO_RESTFUL_CONFIG_DIR", dir)
+	mu.Lock()
+	tokens = nil // Read the cache again from the new directory
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		tokens = nil
+		mu.Unlock()
+	})
+	return dir
+}
+
+// Function 'TestFetchGrants' checks the forms the client credentials and password grants send.
+func TestFetchGrants(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		want      url.Values
+		basicAuth bool
+	}{
+		{
+			name:      "client credentials, confidential client",
+			cfg:       Config{Grant: GrantClientCredentials, ClientID: "app", ClientSecret: "s3cret", Scope: "read write"},
+			want:      url.Values{"grant_type": {"client_credentials"}, "scope": {"read write"}},
+			basicAuth: true,
+		},
+		{
+			name:      "client credentials is the default grant",
+			cfg:       Config{ClientID: "app", ClientSecret: "s3cret"},
+			want:      url.Values{"grant_type": {"client_credentials"}},
+			basicAuth: true,
+		},
+		{
+			name: "password, public client",
+			cfg:  Config{Grant: GrantPassword, ClientID: "app", Username: "ann", Password: "pa ss"},
+			want: url.Values{"grant_type": {"password"}, "username": {"ann"}, "password": {"pa ss"}, "client_id": {"app"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTempCache(t)
+			endpoint := newTokenEndpoint(t, map[string]any{"access_token": "abc", "token_type": "bearer", "expires_in": 3600})
+			test.cfg.TokenURL = endpoint.URL
+
+			token, err := Fetch(context.Background(), test.cfg, testSettings, nil)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if got := token.Authorization(); got != "Bearer abc" {
+				t.Errorf("Authorization() = %q, want %q", got, "Bearer abc")
+			}
+			if lifetime := token.Expiry.Sub(token.Issued); lifetime != time.Hour {
+				t.Errorf("token lifetime = %s, want 1h", lifetime)
+			}
+
+			forms := endpoint.requests()
+			if len(forms) != 1 {
+				t.Fatalf("token endpoint got %d requests, want 1", len(forms))
+			}
+			if got := forms[0].Encode(); got != test.want.Encode() {
+				t.Errorf("token request form = %s, want %s", got, test.want.Encode())
+			}
+			username, password, ok := (&http.Request{Header: endpoint.headers[0]}).BasicAuth()
+			if ok != test.basicAuth || (ok && (username != "app" || password != "s3cret")) {
+				t.Errorf("Basic auth = %q, %q, %v, want client credentials: %v", username, password, ok, test.basicAuth)
+			}
+		})
+	}
+}
+
+// Function 'TestFetchErrors' checks that failed token requests are reported and nothing is cached.
+func TestFetchErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]any
+		want     string
+	}{
+		{
+			name:     "error with description",
+			response: map[string]any{"error": "invalid_client", "error_description": "unknown client"},
+			want:     "token endpoint: invalid_client: unknown client",
+		},
+		{
+			name:     "error without description",
+			response: map[string]any{"error": "invalid_grant"},
+			want:     "token endpoint: invalid_grant",
+		},
+		{
+			name:     "no access token",
+			response: map[string]any{"token_type": "bearer"},
+			want:     "without an access token",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTempCache(t)
+			endpoint := newTokenEndpoint(t, test.response)
+			cfg := Config{TokenURL: endpoint.URL, ClientID: "app", ClientSecret: "s3cret"}
+
+			if _, err := Fetch(context.Background(), cfg, testSettings, nil); err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("Fetch() error = %v, want %q", err, test.want)
+			}
+			if _, ok := Cached(cfg); ok {
+				t.Errorf("a failed token request was cached")
+			}
+		})
+	}
+
+	t.Run("not JSON", func(t *testing.T) {
+		useTempCache(t)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "gateway down", http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		_, err := Fetch(context.Background(), Config{TokenURL: server.URL}, testSettings, nil)
+		if err == nil || !strings.Contains(err.Error(), "502") {
+			t.Errorf("Fetch() error = %v, want the status of the answer", err)
+		}
+	})
+}
+
+// Function 'TestFetchCache' checks that a valid token is reused, also after the cache file is read
+// again, and that Forget and changed credentials fetch a new one.
+func TestFetchCache(t *testing.T) {
+	dir := useTempCache(t)
+	endpoint := newTokenEndpoint(t,
+		map[string]any{"access_token": "first", "expires_in": 3600},
+		map[string]any{"access_token": "second", "expires_in": 3600},
+		map[string]any{"access_token": "third", "expires_in": 3600},
+	)
+	cfg := Config{TokenURL: endpoint.URL, ClientID: "app", ClientSecret: "s3cret"}
+
+	fetch := func(cfg Config, want string) {
+		t.Helper()
+		token, err := Fetch(context.Background(), cfg, testSettings, nil)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if token.AccessToken != want {
+			t.Errorf("Fetch() token = %q, want %q", token.AccessToken, want)
+		}
+	}
+
+	fetch(cfg, "first")
+	fetch(cfg, "first")
+	if got := len(endpoint.requests()); got != 1 {
+		t.Errorf("token endpoint got %d requests, want the cached token to be reused", got)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("cache file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("cache file permissions = %o, want 600", perm)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("cache file: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Errorf("cache file holds the client secret: %s", data)
+	}
+
+	// A new process reads the token from the file
+	mu.Lock()
+	tokens = nil
+	mu.Unlock()
+	fetch(cfg, "first")
+
+	if err := Forget(cfg); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	fetch(cfg, "second")
+
+	changed := cfg
+	changed.ClientSecret = "rotated"
+	fetch(changed, "third")
+	fetch(cfg, "second")
+	if got := len(endpoint.requests()); got != 3 {
+		t.Errorf("token endpoint got %d requests, want 3", got)
+	}
+}
+
+// Function 'TestFetchRefresh' checks that an expired token is refreshed with its refresh token.
+func TestFetchRefresh(t *testing.T) {
+	tests := []struct {
+		name        string
+		responses   []map[string]any
+		wantToken   string
+		wantRefresh string
+		wantGrants  []string
+	}{
+		{
+			name:        "refresh keeps the refresh token",
+			responses:   []map[string]any{{"access_token": "refreshed", "expires_in": 3600}},
+			wantToken:   "refreshed",
+			wantRefresh: "old-refresh",
+			wantGrants:  []string{GrantRefreshToken},
+		},
+		{
+			name:        "refresh rotates the refresh token",
+			responses:   []map[string]any{{"access_token": "refreshed", "refresh_token": "new-refresh"}},
+			wantToken:   "refreshed",
+			wantRefresh: "new-refresh",
+			wantGrants:  []string{GrantRefreshToken},
+		},
+		{
+			name: "failed refresh requests a new token",
+			responses: []map[string]any{
+				{"error": "invalid_grant"},
+				{"access_token": "new"},
+			},
+			wantToken:  "new",
+			wantGrants: []string{GrantRefreshToken, GrantClientCredentials},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTempCache(t)
+			endpoint := newTokenEndpoint(t, test.responses...)
+			cfg := Config{TokenURL: endpoint.URL, ClientID: "app", ClientSecret: "s3cret"}
+
+			issued := time.Now().Add(-time.Hour)
+			expired := Token{AccessToken: "expired", RefreshToken: "old-refresh", Issued: issued, Expiry: issued.Add(time.Hour)}
+			if err := store(cfg, expired); err != nil {
+				t.Fatalf("store() error = %v", err)
+			}
+
+			token, err := Fetch(context.Background(), cfg, testSettings, nil)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if token.AccessToken != test.wantToken || token.RefreshToken != test.wantRefresh {
+				t.Errorf("Fetch() = %q with refresh token %q, want %q with %q",
+					token.AccessToken, token.RefreshToken, test.wantToken, test.wantRefresh)
+			}
+
+			forms := endpoint.requests()
+			var grants []string
+			for _, form := range forms {
+				grants = append(grants, form.Get("grant_type"))
+			}
+			if strings.Join(grants, ",") != strings.Join(test.wantGrants, ",") {
+				t.Errorf("grants sent = %v, want %v", grants, test.wantGrants)
+			}
+			if got := forms[0].Get("refresh_token"); got != "old-refresh" {
+				t.Errorf("refresh request sent refresh token %q, want %q", got, "old-refresh")
+			}
+			if cached, _ := Cached(cfg); cached.AccessToken != test.wantToken {
+				t.Errorf("cached token = %q, want %q", cached.AccessToken, test.wantToken)
+			}
+		})
+	}
+}
+
+// Function 'TestTokenValid' checks when a token counts as expired.
+func TestTokenValid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		token Token
+		want  bool
+	}{
+		{"no access token", Token{}, false},
+		{"no expiry", Token{AccessToken: "t"}, true},
+		{"expired", Token{AccessToken: "t", Issued: now.Add(-2 * time.Hour), Expiry: now.Add(-time.Hour)}, false},
+		{"valid beyond the leeway", Token{AccessToken: "t", Issued: now, Expiry: now.Add(time.Hour)}, true},
+		{"inside the leeway", Token{AccessToken: "t", Issued: now.Add(-time.Hour), Expiry: now.Add(30 * time.Second)}, false},
+		{"short lived, first half", Token{AccessToken: "t", Issued: now.Add(-5 * time.Second), Expiry: now.Add(15 * time.Second)}, true},
+		{"short lived, second half", Token{AccessToken: "t", Issued: now.Add(-15 * time.Second), Expiry: now.Add(5 * time.Second)}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.token.Valid(); got != test.want {
+				t.Errorf("Valid() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// Function 'TestAuthorizationCode' runs the authorization code grant against the fake token
+// endpoint, with a browser that follows the redirect the way the test case says.
+func TestAuthorizationCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		redirect func(params url.Values) url.Values // Query the browser is redirected with
+		wantErr  string
+	}{
+		{
+			name: "code exchanged",
+			redirect: func(params url.Values) url.Values {
+				return url.Values{"code": {"the-code"}, "state": {params.Get("state")}}
+			},
+		},
+		{
+			name: "state mismatch",
+			redirect: func(params url.Values) url.Values {
+				return url.Values{"code": {"the-code"}, "state": {"forged"}}
+			},
+			wantErr: "the state does not match",
+		},
+		{
+			name: "access denied",
+			redirect: func(params url.Values) url.Values {
+				return url.Values{"error": {"access_denied"}, "error_description": {"user cancelled"}, "state": {params.Get("state")}}
+			},
+			wantErr: "authorization failed: access_denied user cancelled",
+		},
+		{
+			name: "no code",
+			redirect: func(params url.Values) url.Values {
+				return url.Values{"state": {params.Get("state")}}
+			},
+			wantErr: "no code in the redirect",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTempCache(t)
+			endpoint := newTokenEndpoint(t, map[string]any{"access_token": "abc", "expires_in": 3600})
+			cfg := Config{
+				Grant:    GrantAuthorizationCode,
+				TokenURL: endpoint.URL,
+				AuthURL:  "https://auth.example.com/authorize?audience=api",
+				ClientID: "app",
+				Scope:    "openid",
+			}
+
+			var params url.Values
+			browser := func(authURL string) {
+				parsed, err := url.Parse(authURL)
+				if err != nil {
+					t.Errorf("invalid authorization URL %q: %v", authURL, err)
+					return
+				}
+				params = parsed.Query()
+				response, err := http.Get(params.Get("redirect_uri") + "?" + test.redirect(params).Encode())
+				if err != nil {
+					t.Errorf("following the redirect: %v", err)
+					return
+				}
+				response.Body.Close()
+			}
+
+			token, err := Fetch(context.Background(), cfg, testSettings, browser)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("Fetch() error = %v, want %q", err, test.wantErr)
+				}
+				if got := len(endpoint.requests()); got != 0 {
+					t.Errorf("token endpoint got %d requests, want none", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if token.AccessToken != "abc" {
+				t.Errorf("Fetch() token = %q, want %q", token.AccessToken, "abc")
+			}
+
+			for key, want := range map[string]string{
+				"response_type": "code", "client_id": "app", "scope": "openid",
+				"audience": "api", "code_challenge_method": "S256",
+			} {
+				if got := params.Get(key); got != want {
+					t.Errorf("authorization URL %s = %q, want %q", key, got, want)
+				}
+			}
+			if redirect := params.Get("redirect_uri"); !strings.HasPrefix(redirect, "http://127.0.0.1:") || !strings.HasSuffix(redirect, CallbackPath) {
+				t.Errorf("redirect_uri = %q, want a loopback URL", redirect)
+			}
+
+			forms := endpoint.requests()
+			if len(forms) != 1 {
+				t.Fatalf("token endpoint got %d requests, want 1", len(forms))
+			}
+			form := forms[0]
+			if form.Get("grant_type") != GrantAuthorizationCode || form.Get("code") != "the-code" ||
+				form.Get("redirect_uri") != params.Get("redirect_uri") || form.Get("client_id") != "app" {
+				t.Errorf("token request form = %v", form)
+			}
+			challenge := sha256.Sum256([]byte(form.Get("code_verifier")))
+			if got := base64.RawURLEncoding.EncodeToString(challenge[:]); got != params.Get("code_challenge") {
+				t.Errorf("code verifier does not match the code challenge")
+			}
+		})
+	}
+}
+
+// Function 'TestAuthorizationCodeCancelled' checks that the grant stops waiting when the context ends.
+func TestAuthorizationCodeCancelled(t *testing.T) {
+	useTempCache(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := Config{Grant: GrantAuthorizationCode, TokenURL: "http://127.0.0.1:1/token", AuthURL: "https://auth.example.com/authorize"}
+
+	_, err := Fetch(ctx, cfg, testSettings, func(string) { cancel() })
+	if err != context.Canceled {
+		t.Errorf("Fetch() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,160 @@
+package oauth2 // Package 'oauth2' fetches, caches and refreshes OAuth 2.0 access tokens
+
+import (
+	"context"         // For giving up on the redirect
+	"crypto/rand"     // For the code verifier and the state
+	"crypto/sha256"   // For the S256 code challenge
+	"encoding/base64" // For URL safe random strings
+	"errors"          // For incomplete configurations
+	"fmt"             // For formatted errors
+	"net"             // For the loopback listener
+	"net/http"        // For serving the redirect
+	"net/url"         // For building the authorization URL
+	"os/exec"         // For opening the browser
+	"runtime"         // For picking the browser opener
+	"time"            // For the redirect timeout
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// RedirectTimeout is how long the authorization code grant waits for the browser to come back.
+var RedirectTimeout = 5 * time.Minute
+
+// CallbackPath is the path of the loopback redirect URI.
+const CallbackPath = "/callback"
+
+// 'callback' is what the authorization server sent back to the loopback listener.
+type callback struct {
+	code string
+	err  error
+}
+
+// Function 'authorizationCode' runs the authorization code grant with PKCE (RFC 7636). It listens on
+// a loopback port for the redirect, hands the authorization URL to authorize and exchanges the code
+// it gets back for a token.
+func authorizationCode(ctx context.Context, cfg Config, settings httpclient.ClientSettings, authorize func(authURL string)) (Token, error) {
+	if cfg.AuthURL == "" {
+		return Token{}, errors.New("the authorization code grant needs an authorization URL")
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return Token{}, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return Token{}, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	port := cfg.RedirectPort
+	if port == "" {
+		port = "0"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		return Token{}, fmt.Errorf("listening for the redirect: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr(), CallbackPath)
+
+	authURL, err := url.Parse(cfg.AuthURL)
+	if err != nil {
+		listener.Close()
+		return Token{}, fmt.Errorf("invalid authorization URL: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", cfg.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if cfg.Scope != "" {
+		query.Set("scope", cfg.Scope)
+	}
+	authURL.RawQuery = query.Encode()
+
+	// Serve the redirect until the first answer arrives
+	results := make(chan callback, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != CallbackPath {
+			http.NotFound(w, r)
+			return
+		}
+		params := r.URL.Query()
+		result := callback{code: params.Get("code")}
+		switch {
+		case params.Get("error") != "":
+			result.err = fmt.Errorf("authorization failed: %s %s", params.Get("error"), params.Get("error_description"))
+		case params.Get("state") != state:
+			result.err = errors.New("authorization failed: the state does not match")
+		case result.code == "":
+			result.err = errors.New("authorization failed: no code in the redirect")
+		}
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete, you can close this window and return to go-restful.")
+		}
+		select {
+		case results <- result:
+		default: // Only the first answer counts
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if authorize != nil {
+		authorize(authURL.String())
+	}
+
+	timeout := time.NewTimer(RedirectTimeout)
+	defer timeout.Stop()
+	var result callback
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	case <-timeout.C:
+		return Token{}, fmt.Errorf("no authorization within %s", RedirectTimeout)
+	}
+	if result.err != nil {
+		return Token{}, result.err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", GrantAuthorizationCode)
+	form.Set("code", result.code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+	if cfg.ClientSecret != "" {
+		form.Set("client_id", cfg.ClientID) // Public clients get it added by exchange
+	}
+	return exchange(ctx, cfg, settings, form)
+}
+
+// Function 'OpenBrowser' asks the desktop to open a URL in the default browser.
+func OpenBrowser(target string) error {
+	var command *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		command = exec.Command("open", target)
+	case "windows":
+		command = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		command = exec.Command("xdg-open", target)
+	}
+	if err := command.Start(); err != nil {
+		return err
+	}
+	go command.Wait() // Reap the opener, its exit status does not matter
+	return nil
+}
+
+// Function 'randomString' returns n random bytes, URL safe base64 encoded.
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,44 @@
+package render // Package 'render' turns response bodies into something readable, picking a renderer by Content-Type
+
+import (
+	"bytes"        // For reading the body
+	"encoding/csv" // For splitting records
+	"strings"      // For finding the delimiter
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being rendered
+)
+
+// Function 'renderCSV' reads a CSV or TSV body into a table. The first record is taken as the column
+// names; the delimiter is a tab for TSV, else whichever of , ; | and tab the first line uses most.
+func renderCSV(response httpclient.HttpResponseDetails) (Output, error) {
+	reader := csv.NewReader(bytes.NewReader(response.Body))
+	reader.FieldsPerRecord = -1 // Rows may be ragged
+	reader.LazyQuotes = true
+	if MediaType(response) == "text/tab-separated-values" {
+		reader.Comma = '\t'
+	} else {
+		reader.Comma = guessDelimiter(response.Body)
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return Output{}, err
+	}
+	if len(rows) == 0 {
+		rows = [][]string{{}}
+	}
+	return Output{Table: rows}, nil
+}
+
+// Function 'guessDelimiter' returns the delimiter the first line of a CSV body uses most, ',' when
+// it uses none.
+func guessDelimiter(body []byte) rune {
+	first, _, _ := bytes.Cut(body, []byte("\n"))
+	delimiter, most := ',', 0
+	for _, candidate := range ",;\t|" {
+		if count := strings.Count(string(first), string(candidate)); count > most {
+			delimiter, most = candidate, count
+		}
+	}
+	return delimiter
+}
@@ -0,0 +1,49 @@
+package render // Package 'render' turns response bodies into something readable, picking a renderer by Content-Type
+
+import (
+	"fmt"     // For offsets and byte values
+	"strings" // For building the dump
+
+	"github.com/rivo/tview" // For escaping text shown with color tags
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being rendered
+)
+
+// MaxHexDump is the number of body bytes shown by the hex dump, the rest is left out.
+const MaxHexDump = 256 * 1024
+
+// Function 'renderHex' dumps a body as 'hexdump -C' does: the offset, 16 bytes in hex and the same
+// bytes as ASCII, with '.' for what is not printable.
+func renderHex(response httpclient.HttpResponseDetails) (Output, error) {
+	body := response.Body
+	b := &strings.Builder{}
+	for offset := 0; offset < len(body) && offset < MaxHexDump; offset += 16 {
+		chunk := body[offset:min(offset+16, len(body))]
+		fmt.Fprintf(b, "[gray]%08x[-]  ", offset)
+		for i := 0; i < 16; i++ {
+			switch {
+			case i < len(chunk):
+				fmt.Fprintf(b, "%02x ", chunk[i])
+			default:
+				b.WriteString("   ")
+			}
+			if i == 7 {
+				b.WriteByte(' ')
+			}
+		}
+		ascii := make([]byte, len(chunk))
+		for i, c := range chunk {
+			if c >= 0x20 && c < 0x7f {
+				ascii[i] = c
+			} else {
+				ascii[i] = '.'
+			}
+		}
+		b.WriteString(" |[orange]" + tview.Escape(string(ascii)) + "[-]|\n")
+	}
+	if len(body) > MaxHexDump {
+		fmt.Fprintf(b, "[gray]... %s more not shown[-]\n", formatSize(len(body)-MaxHexDump))
+	}
+	fmt.Fprintf(b, "[gray]%08x[-]", len(body))
+	return Output{Text: b.String()}, nil
+}
@@ -0,0 +1,77 @@
+package render // Package 'render' turns response bodies into something readable, picking a renderer by Content-Type
+
+import (
+	"bytes"        // For reading the body
+	"fmt"          // For the metadata lines
+	"image"        // For reading the size and color model without decoding the pixels
+	"image/color"  // For naming color models
+	_ "image/gif"  // Registering the GIF format
+	_ "image/jpeg" // Registering the JPEG format
+	_ "image/png"  // Registering the PNG format
+	"net/http"     // For recognizing image formats
+	"strings"      // For building the preview
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being rendered
+)
+
+// Function 'sniffImage' claims bodies that look like an image, whatever their Content-Type.
+func sniffImage(response httpclient.HttpResponseDetails) bool {
+	return strings.HasPrefix(http.DetectContentType(response.Body), "image/")
+}
+
+// Function 'renderImage' previews the metadata of an image: its format, dimensions, color model and
+// size. Formats that cannot be decoded (WebP, BMP, ICO) still show their type and size.
+func renderImage(response httpclient.HttpResponseDetails) (Output, error) {
+	detected := http.DetectContentType(response.Body)
+	if !strings.HasPrefix(detected, "image/") {
+		return Output{}, fmt.Errorf("the body is not an image")
+	}
+
+	b := &strings.Builder{}
+	field := func(name, value string) {
+		fmt.Fprintf(b, "[blue]%-12s[-] %s\n", name, value)
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(response.Body))
+	if err != nil {
+		field("Type", detected)
+		field("Size", formatSize(len(response.Body)))
+		return Output{Text: b.String() + "\n[gray]Dimensions are only read from PNG, JPEG and GIF images[-]"}, nil
+	}
+
+	field("Format", strings.ToUpper(format))
+	field("Type", detected)
+	field("Dimensions", fmt.Sprintf("%d × %d px", config.Width, config.Height))
+	field("Color model", colorModelName(config.ColorModel))
+	if format == "png" && len(response.Body) > 28 { // The IHDR chunk holds the bit depth and the interlace method
+		field("Bit depth", fmt.Sprint(response.Body[24]))
+		field("Interlaced", fmt.Sprint(response.Body[28] == 1))
+	}
+	field("Size", formatSize(len(response.Body)))
+	return Output{Text: strings.TrimSuffix(b.String(), "\n")}, nil
+}
+
+// Function 'colorModelName' names the color model of an image.
+func colorModelName(model color.Model) string {
+	if palette, ok := model.(color.Palette); ok {
+		return fmt.Sprintf("Paletted, %d colors", len(palette))
+	}
+	switch model {
+	case color.RGBAModel:
+		return "RGBA"
+	case color.RGBA64Model:
+		return "RGBA, 16 bits"
+	case color.NRGBAModel:
+		return "NRGBA"
+	case color.NRGBA64Model:
+		return "NRGBA, 16 bits"
+	case color.GrayModel:
+		return "Grayscale"
+	case color.Gray16Model:
+		return "Grayscale, 16 bits"
+	case color.YCbCrModel:
+		return "YCbCr"
+	case color.CMYKModel:
+		return "CMYK"
+	}
+	return "Unknown"
+}
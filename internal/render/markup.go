@@ -0,0 +1,339 @@
+package render // Package 'render' turns response bodies into something readable, picking a renderer by Content-Type
+
+import (
+	"fmt"     // For errors
+	"strings" // For scanning and indenting markup
+
+	"github.com/rivo/tview" // For escaping text shown with color tags
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being rendered
+)
+
+// Kinds of markup tokens.
+const (
+	markupText      = iota // Text between tags
+	markupRaw              // Contents of an HTML script, style, textarea or title element, kept as they are
+	markupStart            // <name attr="value">
+	markupEnd              // </name>
+	markupEmpty            // <name/>
+	markupComment          // <!-- comment -->
+	markupDirective        // <?xml ...?>, <!DOCTYPE ...> or <![CDATA[...]]>
+)
+
+// 'markupToken' is a piece of an XML or HTML document.
+type markupToken struct {
+	kind  int
+	name  string       // Element name, as written
+	attrs []markupAttr // Attributes of a start tag, in order
+	text  string       // Text, raw contents, comment or directive, as written
+}
+
+// 'markupAttr' is an attribute of a start tag.
+type markupAttr struct {
+	name     string
+	value    string // Value without its quotes
+	hasValue bool   // Unset for boolean HTML attributes such as 'disabled'
+}
+
+// 'voidElements' are the HTML elements that never have an end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// 'rawElements' are the HTML elements whose contents are not markup.
+var rawElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// 'impliedEnds' are the HTML elements whose end tag may be left out when a sibling starts, with the
+// elements that end them.
+var impliedEnds = map[string][]string{
+	"li": {"li"}, "p": {"p"}, "option": {"option"}, "tr": {"tr"},
+	"td": {"td", "th"}, "th": {"td", "th"}, "dt": {"dt", "dd"}, "dd": {"dt", "dd"},
+}
+
+// Function 'renderXML' indents and colors an XML body.
+func renderXML(response httpclient.HttpResponseDetails) (Output, error) {
+	return renderMarkup(string(response.Body), false)
+}
+
+// Function 'renderHTML' indents and colors an HTML body.
+func renderHTML(response httpclient.HttpResponseDetails) (Output, error) {
+	return renderMarkup(string(response.Body), true)
+}
+
+// Function 'renderMarkup' indents and colors a document. Malformed markup is shown as well as it can
+// be rather than refused; a body without a single element is an error.
+func renderMarkup(src string, html bool) (Output, error) {
+	tokens := tokenizeMarkup(src, html)
+	for _, token := range tokens {
+		if token.kind == markupStart || token.kind == markupEmpty {
+			return Output{Text: prettyMarkup(tokens, html)}, nil
+		}
+	}
+	return Output{}, fmt.Errorf("the body has no elements")
+}
+
+// Function 'tokenizeMarkup' splits a document into tokens. It is lenient: anything it does not
+// understand becomes text.
+func tokenizeMarkup(src string, html bool) []markupToken {
+	var tokens []markupToken
+	for pos := 0; pos < len(src); {
+		if src[pos] != '<' {
+			end := strings.IndexByte(src[pos:], '<')
+			if end < 0 {
+				end = len(src) - pos
+			} else if end == 0 {
+				end = 1 // A '<' that does not start a tag
+			}
+			tokens = appendText(tokens, src[pos:pos+end])
+			pos += end
+			continue
+		}
+
+		rest := src[pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			body, next := until(src, pos+4, "-->")
+			tokens = append(tokens, markupToken{kind: markupComment, text: body})
+			pos = next
+		case strings.HasPrefix(rest, "<![CDATA["):
+			body, next := until(src, pos, "]]>")
+			tokens = append(tokens, markupToken{kind: markupDirective, text: body + "]]>"})
+			pos = next
+		case strings.HasPrefix(rest, "<?"):
+			body, next := until(src, pos, "?>")
+			tokens = append(tokens, markupToken{kind: markupDirective, text: body + "?>"})
+			pos = next
+		case strings.HasPrefix(rest, "<!"):
+			body, next := until(src, pos, ">")
+			tokens = append(tokens, markupToken{kind: markupDirective, text: body + ">"})
+			pos = next
+		case strings.HasPrefix(rest, "</"):
+			body, next := until(src, pos+2, ">")
+			tokens = append(tokens, markupToken{kind: markupEnd, name: strings.TrimSpace(body)})
+			pos = next
+		case len(rest) > 1 && isNameStart(rest[1]):
+			var token markupToken
+			token, pos = parseTag(src, pos+1)
+			tokens = append(tokens, token)
+			if html && token.kind == markupStart && rawElements[strings.ToLower(token.name)] {
+				end := strings.Index(strings.ToLower(src[pos:]), "</"+strings.ToLower(token.name))
+				if end < 0 {
+					end = len(src) - pos
+				}
+				tokens = append(tokens, markupToken{kind: markupRaw, text: src[pos : pos+end]})
+				pos += end
+			}
+		default:
+			tokens = appendText(tokens, "<")
+			pos++
+		}
+	}
+	return tokens
+}
+
+// Function 'appendText' adds text to the tokens, joining it to text right before it.
+func appendText(tokens []markupToken, text string) []markupToken {
+	if n := len(tokens); n > 0 && tokens[n-1].kind == markupText {
+		tokens[n-1].text += text
+		return tokens
+	}
+	return append(tokens, markupToken{kind: markupText, text: text})
+}
+
+// Function 'until' returns the source from start up to a terminator, and the position after the
+// terminator. Without one it returns everything left.
+func until(src string, start int, terminator string) (string, int) {
+	end := strings.Index(src[start:], terminator)
+	if end < 0 {
+		return src[start:], len(src)
+	}
+	return src[start : start+end], start + end + len(terminator)
+}
+
+// Function 'parseTag' reads a start tag from its name on, and returns it with the position after it.
+func parseTag(src string, pos int) (markupToken, int) {
+	token := markupToken{kind: markupStart}
+	start := pos
+	for pos < len(src) && !strings.ContainsRune(" \t\r\n/>", rune(src[pos])) {
+		pos++
+	}
+	token.name = src[start:pos]
+
+	for pos < len(src) {
+		switch {
+		case strings.ContainsRune(" \t\r\n", rune(src[pos])):
+			pos++
+		case src[pos] == '>':
+			return token, pos + 1
+		case strings.HasPrefix(src[pos:], "/>"):
+			token.kind = markupEmpty
+			return token, pos + 2
+		case src[pos] == '/':
+			pos++
+		default:
+			var attr markupAttr
+			start := pos
+			for pos < len(src) && !strings.ContainsRune(" \t\r\n=/>", rune(src[pos])) {
+				pos++
+			}
+			attr.name = src[start:pos]
+			if pos < len(src) && src[pos] == '=' {
+				attr.hasValue = true
+				pos++
+				if pos < len(src) && (src[pos] == '"' || src[pos] == '\'') {
+					attr.value, pos = until(src, pos+1, string(src[pos]))
+				} else {
+					start := pos
+					for pos < len(src) && !strings.ContainsRune(" \t\r\n>", rune(src[pos])) {
+						pos++
+					}
+					attr.value = src[start:pos]
+				}
+			}
+			token.attrs = append(token.attrs, attr)
+		}
+	}
+	return token, pos
+}
+
+// Function 'isNameStart' reports whether a tag name can start with c.
+func isNameStart(c byte) bool {
+	return c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// Function 'prettyMarkup' writes the tokens one element per line, indented by depth. An element
+// holding nothing but a line of text stays on one line. HTML void elements and left out end tags
+// do not throw the indentation off.
+func prettyMarkup(tokens []markupToken, html bool) string {
+	b := &strings.Builder{}
+	var open []string // Names of the elements containing the current token
+	sameName := func(a, b string) bool {
+		if html {
+			return strings.EqualFold(a, b)
+		}
+		return a == b
+	}
+	line := func(s string) {
+		b.WriteString(strings.Repeat("  ", len(open)))
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch token.kind {
+		case markupText:
+			for _, text := range strings.Split(token.text, "\n") {
+				if text = strings.TrimSpace(text); text != "" {
+					line(tview.Escape(text))
+				}
+			}
+		case markupRaw:
+			for _, text := range dedent(token.text) {
+				line("[orange]" + tview.Escape(text) + "[-]")
+			}
+		case markupComment:
+			for _, text := range dedent("<!--" + token.text + "-->") {
+				line("[gray]" + tview.Escape(text) + "[-]")
+			}
+		case markupDirective:
+			line("[mediumpurple]" + tview.Escape(token.text) + "[-]")
+		case markupEmpty:
+			line(formatTag(token, "/>"))
+		case markupStart:
+			name := strings.ToLower(token.name)
+			if html && voidElements[name] {
+				line(formatTag(token, ">"))
+				if i+1 < len(tokens) && tokens[i+1].kind == markupEnd && sameName(tokens[i+1].name, token.name) {
+					i++ // <br></br>
+				}
+				continue
+			}
+			if html && len(open) > 0 {
+				for _, ended := range impliedEnds[name] {
+					if strings.EqualFold(open[len(open)-1], ended) {
+						open = open[:len(open)-1]
+						break
+					}
+				}
+			}
+			switch {
+			case i+1 < len(tokens) && tokens[i+1].kind == markupEnd && sameName(tokens[i+1].name, token.name):
+				line(formatTag(token, ">") + formatEnd(token.name))
+				i++
+			case i+2 < len(tokens) && (tokens[i+1].kind == markupText || tokens[i+1].kind == markupRaw) &&
+				tokens[i+2].kind == markupEnd && sameName(tokens[i+2].name, token.name) &&
+				!strings.Contains(strings.TrimSpace(tokens[i+1].text), "\n"):
+				line(formatTag(token, ">") + tview.Escape(strings.TrimSpace(tokens[i+1].text)) + formatEnd(token.name))
+				i += 2
+			default:
+				line(formatTag(token, ">"))
+				open = append(open, token.name)
+			}
+		case markupEnd:
+			for depth := len(open) - 1; depth >= 0; depth-- {
+				if sameName(open[depth], token.name) {
+					open = open[:depth]
+					break
+				}
+			}
+			line(formatEnd(token.name))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Function 'formatTag' colors a start tag, closed by '>' or '/>'.
+func formatTag(token markupToken, close string) string {
+	b := &strings.Builder{}
+	b.WriteString("<[blue]" + tview.Escape(token.name) + "[-]")
+	for _, attr := range token.attrs {
+		b.WriteString(" [green]" + tview.Escape(attr.name) + "[-]")
+		if attr.hasValue {
+			quote := `"`
+			if strings.Contains(attr.value, `"`) {
+				quote = "'"
+			}
+			b.WriteString("=[orange]" + quote + tview.Escape(attr.value) + quote + "[-]")
+		}
+	}
+	b.WriteString(close)
+	return b.String()
+}
+
+// Function 'formatEnd' colors an end tag.
+func formatEnd(name string) string {
+	return "</[blue]" + tview.Escape(name) + "[-]>"
+}
+
+// Function 'dedent' splits text into lines, without blank lines at either end and without the
+// indentation all lines share.
+func dedent(text string) []string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	shared := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := len(l) - len(strings.TrimLeft(l, " \t"))
+		if shared < 0 || indent < shared {
+			shared = indent
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= shared && shared > 0 {
+			lines[i] = strings.TrimRight(l[shared:], " \t")
+		} else {
+			lines[i] = strings.TrimSpace(l)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,148 @@
+package render // Package 'render' turns response bodies into something readable, picking a renderer by Content-Type
+
+import (
+	"fmt"          // For sizes
+	"mime"         // For parsing the Content-Type header
+	"strings"      // For matching media types
+	"unicode/utf8" // For telling text from binary bodies
+
+	"github.com/rivo/tview" // For escaping text shown with color tags
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being rendered
+)
+
+// 'Output' is a rendered body. One of its fields is set.
+type Output struct {
+	Text  string      // Text with tview color tags
+	Table [][]string  // Rows of a table, the first one holds the column names
+	JSON  interface{} // Decoded JSON, shown as a tree
+}
+
+// 'Renderer' renders the bodies of some media types.
+type Renderer struct {
+	Name   string                                                        // Shown by the viewer, e.g. XML
+	Types  []string                                                      // Media types: exact (text/csv), a suffix (+xml) or a whole type (image/*)
+	Sniff  func(response httpclient.HttpResponseDetails) bool            // Optional, claims bodies whose Content-Type no renderer takes
+	Render func(response httpclient.HttpResponseDetails) (Output, error) // Renders the body
+}
+
+// 'renderers' are tried in order, the first one taking the media type wins.
+var renderers []Renderer
+
+// 'textRenderer' shows bodies no other renderer takes.
+var textRenderer = Renderer{
+	Name: "Text",
+	Render: func(response httpclient.HttpResponseDetails) (Output, error) {
+		return Output{Text: tview.Escape(string(response.Body))}, nil
+	},
+}
+
+// Registering the built in renderers, the more specific types first
+func init() {
+	renderers = []Renderer{
+		{Name: "JSON", Types: []string{"application/json", "+json"}, Sniff: sniffJSON, Render: renderJSON},
+		{Name: "HTML", Types: []string{"text/html", "application/xhtml+xml"}, Render: renderHTML},
+		{Name: "XML", Types: []string{"application/xml", "text/xml", "+xml"}, Render: renderXML},
+		{Name: "YAML", Types: []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml", "+yaml"}, Render: renderYAML},
+		{Name: "CSV", Types: []string{"text/csv", "application/csv", "text/tab-separated-values"}, Render: renderCSV},
+		{Name: "Image", Types: []string{"image/*"}, Sniff: sniffImage, Render: renderImage},
+		{Name: "Hex", Sniff: sniffBinary, Render: renderHex},
+	}
+}
+
+// Function 'Register' adds a renderer ahead of those already registered, so it can take over their types.
+func Register(renderer Renderer) {
+	renderers = append([]Renderer{renderer}, renderers...)
+}
+
+// Function 'Lookup' returns the renderer of a response: the first one taking its media type, else the
+// first one sniffing the body, else one showing the body as text.
+func Lookup(response httpclient.HttpResponseDetails) Renderer {
+	mediaType := MediaType(response)
+	for _, renderer := range renderers {
+		for _, pattern := range renderer.Types {
+			if matchType(pattern, mediaType) {
+				return renderer
+			}
+		}
+	}
+	for _, renderer := range renderers {
+		if renderer.Sniff != nil && renderer.Sniff(response) {
+			return renderer
+		}
+	}
+	return textRenderer
+}
+
+// Function 'Raw' returns the body as it was received: text as it is, binary bodies as a hex dump.
+func Raw(response httpclient.HttpResponseDetails) Output {
+	if sniffBinary(response) {
+		output, _ := renderHex(response)
+		return output
+	}
+	return Output{Text: tview.Escape(string(response.Body))}
+}
+
+// Function 'MediaType' returns the lower case media type of the Content-Type header, without its
+// parameters, or "" when there is none.
+func MediaType(response httpclient.HttpResponseDetails) string {
+	values := response.HeaderValues("Content-Type")
+	if len(values) == 0 {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(values[0])
+	if err != nil {
+		mediaType, _, _ = strings.Cut(values[0], ";")
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// Function 'matchType' reports whether a media type matches a pattern of Renderer.Types.
+func matchType(pattern, mediaType string) bool {
+	switch {
+	case mediaType == "":
+		return false
+	case strings.HasPrefix(pattern, "+"):
+		return strings.HasSuffix(mediaType, pattern)
+	case strings.HasSuffix(pattern, "/*"):
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == mediaType
+}
+
+// Function 'sniffJSON' claims bodies that were decoded as JSON, whatever their Content-Type.
+func sniffJSON(response httpclient.HttpResponseDetails) bool {
+	return response.JsonData != nil
+}
+
+// Function 'renderJSON' hands the decoded body to the tree.
+func renderJSON(response httpclient.HttpResponseDetails) (Output, error) {
+	if response.JsonData == nil {
+		return Output{}, fmt.Errorf("the body is not valid JSON")
+	}
+	return Output{JSON: response.JsonData}, nil
+}
+
+// Function 'sniffBinary' reports whether the body is not text: it is not UTF-8 or holds NUL bytes.
+func sniffBinary(response httpclient.HttpResponseDetails) bool {
+	start := response.Body
+	if len(start) > 8192 {
+		start = start[:8192]
+		for i := 0; i < utf8.UTFMax && !utf8.Valid(start); i++ {
+			start = start[:len(start)-1] // Dropping a character cut in half
+		}
+	}
+	return !utf8.Valid(start) || strings.IndexByte(string(start), 0) >= 0
+}
+
+// Function 'formatSize' formats a byte count as B, KB or MB.
+func formatSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
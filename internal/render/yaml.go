@@ -0,0 +1,130 @@
+package render // Package 'render' turns response bodies into something readable, picking a renderer by Content-Type
+
+import (
+	"errors"  // For telling the end of the documents from an error
+	"io"      // For the end of the documents
+	"regexp"  // For recognizing keys and scalars
+	"strings" // For splitting lines
+
+	"github.com/rivo/tview" // For escaping text shown with color tags
+	"gopkg.in/yaml.v3"      // For checking the body is valid YAML
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being rendered
+)
+
+// Patterns recognizing the parts of a YAML line
+var (
+	yamlKey   = regexp.MustCompile(`^(\s*(?:-\s+)*)("(?:[^"\\]|\\.)*"|'[^']*'|[^\s#'"\-][^:#]*?|-[^\s:#][^:#]*?)(\s*:)(\s+|$)(.*)$`)
+	yamlItem  = regexp.MustCompile(`^(\s*(?:-\s+)+)(.*)$`)
+	yamlInt   = regexp.MustCompile(`^[-+]?(?:0|[1-9][0-9_]*|0x[0-9a-fA-F_]+|0o[0-7_]+)$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(?:[0-9][0-9_]*)?\.?[0-9_]+(?:[eE][-+]?[0-9]+)?$|^[-+]?\.(?:inf|Inf|INF)$|^\.(?:nan|NaN|NAN)$`)
+	yamlBool  = regexp.MustCompile(`^(?:true|True|TRUE|false|False|FALSE)$`)
+	yamlNull  = regexp.MustCompile(`^(?:~|null|Null|NULL)$`)
+	yamlBlock = regexp.MustCompile(`^[|>][-+0-9]*$`)
+)
+
+// Function 'renderYAML' colors a YAML body as it was written: keys, scalars by type, comments and
+// the text of block scalars. The body is checked to be valid YAML first.
+func renderYAML(response httpclient.HttpResponseDetails) (Output, error) {
+	decoder := yaml.NewDecoder(strings.NewReader(string(response.Body)))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return Output{}, err
+		}
+	}
+
+	b := &strings.Builder{}
+	blockIndent := -1 // Indentation of the key whose block scalar is being read, -1 outside one
+	for i, l := range strings.Split(strings.ReplaceAll(string(response.Body), "\r\n", "\n"), "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		indent := len(l) - len(strings.TrimLeft(l, " "))
+		trimmed := strings.TrimSpace(l)
+		if blockIndent >= 0 && (trimmed == "" || indent > blockIndent) {
+			b.WriteString("[orange]" + tview.Escape(l) + "[-]")
+			continue
+		}
+		blockIndent = -1
+
+		switch {
+		case trimmed == "":
+			b.WriteString(l)
+		case strings.HasPrefix(trimmed, "#"):
+			b.WriteString("[gray]" + tview.Escape(l) + "[-]")
+		case trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "%"):
+			b.WriteString("[mediumpurple]" + tview.Escape(l) + "[-]")
+		default:
+			var value string
+			if m := yamlKey.FindStringSubmatch(l); m != nil {
+				b.WriteString(tview.Escape(m[1]) + "[blue]" + tview.Escape(m[2]) + "[-]" + m[3] + m[4])
+				value = m[5]
+			} else if m := yamlItem.FindStringSubmatch(l); m != nil {
+				b.WriteString(tview.Escape(m[1]))
+				value = m[2]
+			} else {
+				b.WriteString(strings.Repeat(" ", indent))
+				value = trimmed
+			}
+			scalar, comment := splitYAMLComment(value)
+			if yamlBlock.MatchString(strings.TrimSpace(scalar)) {
+				blockIndent = indent
+			}
+			b.WriteString(colorYAMLScalar(scalar))
+			if comment != "" {
+				b.WriteString("[gray]" + tview.Escape(comment) + "[-]")
+			}
+		}
+	}
+	return Output{Text: b.String()}, nil
+}
+
+// Function 'splitYAMLComment' splits a value from a trailing comment, which starts with " #" outside
+// of quotes.
+func splitYAMLComment(value string) (string, string) {
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || value[i-1] == ' ' || value[i-1] == '['):
+			quote = c
+		case c == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return value[:i], value[i:]
+		}
+	}
+	return value, ""
+}
+
+// Function 'colorYAMLScalar' colors a value by its type, with the colors of the JSON tree.
+func colorYAMLScalar(value string) string {
+	trimmed := strings.TrimSpace(value)
+	color := ""
+	switch {
+	case trimmed == "":
+	case yamlNull.MatchString(trimmed):
+		color = "darkcyan"
+	case yamlBool.MatchString(trimmed):
+		color = "mediumpurple"
+	case yamlInt.MatchString(trimmed):
+		color = "red"
+	case yamlFloat.MatchString(trimmed):
+		color = "green"
+	case yamlBlock.MatchString(trimmed), strings.HasPrefix(trimmed, "&"), strings.HasPrefix(trimmed, "*"),
+		strings.HasPrefix(trimmed, "!"), strings.HasPrefix(trimmed, "{"), strings.HasPrefix(trimmed, "["):
+		return tview.Escape(value) // Indicators, anchors, tags and flow collections
+	default:
+		color = "orange"
+	}
+	if color == "" {
+		return value
+	}
+	return "[" + color + "]" + tview.Escape(value) + "[-]"
+}
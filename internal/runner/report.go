@@ -0,0 +1,299 @@
+package runner // Package 'runner' sends the requests of a collection without the UI and checks their responses
+
+import (
+	"encoding/json" // For the JSON report
+	"encoding/xml"  // For the JUnit report
+	"fmt"           // For formatted output
+	"io"            // For writing reports anywhere
+	"strings"       // For building failure messages
+	"time"          // For durations in seconds and milliseconds
+
+	"github.com/SiirRandall/go-restful/internal/assertion" // Checks of the response
+	"github.com/SiirRandall/go-restful/internal/extract"   // Values saved from the response into variables
+)
+
+// Function 'WriteResult' writes a line for a request, followed by a line per check and per variable
+// it sets. The values of variables are left out, they often hold credentials.
+func WriteResult(w io.Writer, result Result) {
+	mark := "✓"
+	if !result.Passed() {
+		mark = "✗"
+	}
+	fmt.Fprintf(w, "%s %s %s", mark, result.Method, result.Path())
+	if result.Error != "" {
+		fmt.Fprintf(w, "\n    error: %s\n", result.Error)
+		return
+	}
+	fmt.Fprintf(w, " - %d %s in %s\n", result.StatusCode, result.StatusText, formatDuration(result.Duration))
+	for _, line := range resultLines(result) {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+}
+
+// Function 'resultLines' describes every check of a request and every variable it sets.
+func resultLines(result Result) []string {
+	var lines []string
+	for _, check := range result.Checks {
+		lines = append(lines, checkLine(check))
+	}
+	for _, variable := range result.Variables {
+		lines = append(lines, variableLine(variable))
+	}
+	return lines
+}
+
+// Function 'WriteSummary' writes the counts of a run: requests, checks and how many of them failed.
+func WriteSummary(w io.Writer, report *Report) {
+	checks, passed := report.Checks()
+	fmt.Fprintf(w, "\n%s, %d failed; %s, %d failed; %s\n", plural(len(report.Results), "request"), report.Failed(),
+		plural(checks, "check"), checks-passed, formatDuration(report.Duration))
+}
+
+// Function 'plural' writes a count with a noun, adding an s unless the count is one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// Function 'checkLine' describes a check with why it failed.
+func checkLine(check assertion.Result) string {
+	switch {
+	case check.Passed:
+		return "✓ " + check.Assertion.String()
+	case check.Error != "":
+		return fmt.Sprintf("✗ %s (%s)", check.Assertion.String(), check.Error)
+	default:
+		return fmt.Sprintf("✗ %s (actual: %s)", check.Assertion.String(), shorten(check.Actual, 200))
+	}
+}
+
+// Function 'variableLine' describes a variable set from the response, or why it was not set.
+func variableLine(variable extract.Result) string {
+	if variable.Error != "" {
+		return fmt.Sprintf("✗ set %s (%s)", variable.Rule.Variable, variable.Error)
+	}
+	return "✓ set " + variable.Rule.Variable
+}
+
+// 'junitSuites' and the types below are the elements of a JUnit XML report: one test suite for
+// the collection with a test case per request.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// Function 'WriteJUnit' writes the report as JUnit XML. Requests that got no response are errors,
+// requests with failed checks are failures listing every check.
+func WriteJUnit(w io.Writer, report *Report) error {
+	suite := junitSuite{
+		Name:      report.Collection,
+		Tests:     len(report.Results),
+		Time:      seconds(report.Duration),
+		Timestamp: report.Started.Format("2006-01-02T15:04:05"),
+	}
+	for _, result := range report.Results {
+		testCase := junitTestCase{
+			Name:      fmt.Sprintf("%s %s", result.Method, result.Name),
+			ClassName: strings.Join(append([]string{report.Collection}, result.Folder...), "."),
+			Time:      seconds(result.Duration),
+		}
+		lines := resultLines(result)
+		switch {
+		case result.Error != "":
+			suite.Errors++
+			testCase.Error = &junitProblem{Message: result.Error, Type: "RequestError", Text: result.URL}
+		case !result.Passed():
+			suite.Failures++
+			testCase.Failure = &junitProblem{
+				Message: failureMessage(result),
+				Type:    "AssertionFailure",
+				Text:    strings.Join(lines, "\n"),
+			}
+		default:
+			if len(lines) > 0 {
+				testCase.SystemOut = &junitOutput{Text: strings.Join(lines, "\n")}
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suites := junitSuites{
+		Name:     report.Collection,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitSuite{suite},
+	}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// Function 'failureMessage' sums up why a request that got a response failed.
+func failureMessage(result Result) string {
+	var parts []string
+	if failed := len(result.Checks) - assertion.Passed(result.Checks); failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d checks failed", failed, len(result.Checks)))
+	}
+	if unset := len(result.Variables) - result.VariablesSet(); unset > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d variables not set", unset, len(result.Variables)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// 'jsonReport' and the types below are the JSON form of a report.
+type jsonReport struct {
+	Collection   string       `json:"collection"`
+	Environment  string       `json:"environment,omitempty"`
+	Started      time.Time    `json:"started"`
+	DurationMs   float64      `json:"durationMs"`
+	Requests     int          `json:"requests"`
+	Failed       int          `json:"failed"`
+	Checks       int          `json:"checks"`
+	ChecksFailed int          `json:"checksFailed"`
+	Results      []jsonResult `json:"results"`
+}
+
+type jsonResult struct {
+	Name       string      `json:"name"`
+	Folder     []string    `json:"folder,omitempty"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Passed     bool        `json:"passed"`
+	Status     int         `json:"status,omitempty"`
+	StatusText string      `json:"statusText,omitempty"`
+	DurationMs float64     `json:"durationMs"`
+	Error      string      `json:"error,omitempty"`
+	Checks     []jsonCheck `json:"checks,omitempty"`
+	Variables  []jsonSet   `json:"variables,omitempty"`
+}
+
+type jsonCheck struct {
+	Check  string `json:"check"` // The assertion as it is written on the Tests page
+	Passed bool   `json:"passed"`
+	Actual string `json:"actual,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type jsonSet struct {
+	Variable string `json:"variable"` // Name of the variable, its value is left out
+	Set      bool   `json:"set"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Function 'WriteJSON' writes the report as indented JSON.
+func WriteJSON(w io.Writer, report *Report) error {
+	checks, passed := report.Checks()
+	out := jsonReport{
+		Collection:   report.Collection,
+		Environment:  report.Environment,
+		Started:      report.Started,
+		DurationMs:   milliseconds(report.Duration),
+		Requests:     len(report.Results),
+		Failed:       report.Failed(),
+		Checks:       checks,
+		ChecksFailed: checks - passed,
+		Results:      []jsonResult{},
+	}
+	for _, result := range report.Results {
+		r := jsonResult{
+			Name:       result.Name,
+			Folder:     result.Folder,
+			Method:     result.Method,
+			URL:        result.URL,
+			Passed:     result.Passed(),
+			Status:     result.StatusCode,
+			StatusText: result.StatusText,
+			DurationMs: milliseconds(result.Duration),
+			Error:      result.Error,
+		}
+		for _, check := range result.Checks {
+			r.Checks = append(r.Checks, jsonCheck{
+				Check:  check.Assertion.String(),
+				Passed: check.Passed,
+				Actual: shorten(check.Actual, 500),
+				Error:  check.Error,
+			})
+		}
+		for _, variable := range result.Variables {
+			r.Variables = append(r.Variables, jsonSet{
+				Variable: variable.Rule.Variable,
+				Set:      variable.Error == "",
+				Error:    variable.Error,
+			})
+		}
+		out.Results = append(out.Results, r)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// Function 'seconds' formats a duration as the seconds JUnit expects.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Function 'milliseconds' returns a duration in milliseconds, rounded to microseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Round(time.Microsecond)) / float64(time.Millisecond)
+}
+
+// Function 'formatDuration' rounds a duration so it stays readable.
+func formatDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Microsecond * 10).String()
+}
+
+// Function 'shorten' keeps the first line of text, cut to max runes.
+func shorten(text string, max int) string {
+	text, _, cut := strings.Cut(text, "\n")
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max]) + "…"
+	} else if cut {
+		return text + "…"
+	}
+	return text
+}
@@ -0,0 +1,185 @@
+package runner // Package 'runner' sends the requests of a collection without the UI and checks their responses
+
+import (
+	"context" // For cancelling a run
+	"fmt"     // For errors
+	"strings" // For folder paths
+	"time"    // For the duration of a run
+
+	"github.com/SiirRandall/go-restful/internal/assertion"             // Checks of the response
+	"github.com/SiirRandall/go-restful/internal/collection"            // Saved requests module
+	"github.com/SiirRandall/go-restful/internal/cookies"               // Cookies carried from one request to the next
+	"github.com/SiirRandall/go-restful/internal/environment"           // Variables substituted into the requests
+	"github.com/SiirRandall/go-restful/internal/extract"               // Values saved from the responses into variables
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module, the same one the UI sends with
+)
+
+// 'Options' selects the requests of a run and how they are sent.
+type Options struct {
+	Environment *environment.Environment  // Variables substituted into every request and set from the responses, nil for none
+	Settings    httpclient.ClientSettings // Global client settings, requests may override single fields
+	Folder      string                    // Only run the requests below this slash separated folder path
+	Request     string                    // Only run the requests with this name, ignoring case
+	Bail        bool                      // Stop after the first request that fails
+	Jar         *cookies.Jar              // Cookies sent with the requests and set by the responses, nil for a new jar kept in memory
+
+	Authorize func(authURL string) // Called with the URL of an OAuth2 login that has to be opened in a browser
+	Progress  func(result Result)  // Called after every request, in the order they are sent
+}
+
+// 'Result' is the outcome of sending one request of the collection.
+type Result struct {
+	Name       string             // Name of the request
+	Folder     []string           // Folders leading to the request inside the collection
+	Method     string             // Method the request was sent with
+	URL        string             // URL the request was sent to, variables and path params substituted, without credentials
+	StatusCode int                // Status code of the response, 0 when none arrived
+	StatusText string             // Reason phrase of the status code
+	Duration   time.Duration      // Total time of the request
+	Error      string             // Why the request could not be sent or no response arrived
+	Checks     []assertion.Result // Outcome of every enabled assertion of the request
+	Variables  []extract.Result   // Values the request saved into variables for the requests after it
+}
+
+// 'Report' is the outcome of a whole run.
+type Report struct {
+	Collection  string        // Name of the collection
+	Environment string        // Name of the environment, empty for none
+	Started     time.Time     // When the first request was sent
+	Duration    time.Duration // Time the whole run took
+	Results     []Result      // One result per request, in the order they were sent
+}
+
+// Function 'Passed' reports whether the request got a response, every check passed and every
+// variable was set. A request without checks passes whatever its status code.
+func (r Result) Passed() bool {
+	return r.Error == "" && assertion.Passed(r.Checks) == len(r.Checks) && r.VariablesSet() == len(r.Variables)
+}
+
+// Function 'VariablesSet' counts the variables the response had a value for.
+func (r Result) VariablesSet() int {
+	set := 0
+	for _, variable := range r.Variables {
+		if variable.Error == "" {
+			set++
+		}
+	}
+	return set
+}
+
+// Function 'Path' returns the folders and name of the request joined with slashes.
+func (r Result) Path() string {
+	return strings.Join(append(append([]string(nil), r.Folder...), r.Name), "/")
+}
+
+// Function 'Failed' counts the requests that did not pass.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Function 'Checks' counts every check of the run and those that passed.
+func (r *Report) Checks() (int, int) {
+	total, passed := 0, 0
+	for _, result := range r.Results {
+		total += len(result.Checks)
+		passed += assertion.Passed(result.Checks)
+	}
+	return total, passed
+}
+
+// Function 'Run' sends the requests of a collection selected by the options one after the other,
+// in the order of the collection, and checks every response against the assertions of its request.
+// It returns an error only when no request is selected; failed requests are part of the report.
+func Run(ctx context.Context, c *collection.Collection, options Options) (*Report, error) {
+	folder := &c.Folder
+	if options.Folder != "" {
+		if folder = c.FolderByPath(options.Folder, false); folder == nil {
+			return nil, fmt.Errorf("no folder %q in collection %s", options.Folder, c.Name)
+		}
+	}
+
+	type selected struct {
+		path    []string
+		request *collection.Request
+	}
+	var requests []selected
+	folder.Walk(func(path []string, r *collection.Request) {
+		if options.Request == "" || strings.EqualFold(r.Name, options.Request) {
+			requests = append(requests, selected{path, r})
+		}
+	})
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no requests to run in collection %s", c.Name)
+	}
+
+	report := &Report{Collection: c.Name, Started: time.Now()}
+	if options.Environment != nil {
+		report.Environment = options.Environment.Name
+	} else {
+		options.Environment = &environment.Environment{} // Holds the variables set by the requests
+	}
+	if options.Jar == nil {
+		options.Jar = cookies.New()
+	}
+	prefix := strings.FieldsFunc(options.Folder, func(r rune) bool { return r == '/' })
+	for _, s := range requests {
+		if ctx.Err() != nil {
+			break
+		}
+		result := send(ctx, s.request, options)
+		result.Folder = append(append([]string(nil), prefix...), s.path...)
+		report.Results = append(report.Results, result)
+		if options.Progress != nil {
+			options.Progress(result)
+		}
+		if options.Bail && !result.Passed() {
+			break
+		}
+	}
+	report.Duration = time.Since(report.Started)
+	return report, nil
+}
+
+// Function 'send' resolves the variables of a request, fetches what its auth needs, sends it,
+// checks the response and sets the variables taken from it, as the Send button of the UI does.
+func send(ctx context.Context, r *collection.Request, options Options) Result {
+	resolved := options.Environment.Apply(r)
+	result := Result{Name: r.Name, Method: resolved.Method, URL: resolved.URL}
+
+	if !resolved.Auth.Prepared() {
+		settings := options.Settings.Apply(resolved.Settings)
+		if err := resolved.Auth.Prepare(ctx, settings, options.Authorize); err != nil {
+			result.Error = fmt.Sprintf("fetching credentials: %v", err)
+			return result
+		}
+	}
+
+	details, err := resolved.HttpRequestDetails(options.Settings)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	// The URL is reported before the auth is applied, an API key in the query stays out of the reports
+	if filled, err := collection.FillPathParams(resolved.URL, resolved.Path); err == nil {
+		result.URL = filled
+	}
+	details.Jar = options.Jar
+
+	response := httpclient.SendHttpRequestContext(ctx, details)
+	result.Duration = response.Timing.Total
+	if response.Error != nil {
+		result.Error = strings.TrimSpace(response.Error.Error())
+		return result
+	}
+	result.StatusCode, result.StatusText = response.StatusCode, response.StatusText
+	result.Checks = assertion.Evaluate(resolved.Assertions, response)
+	result.Variables = extract.Apply(resolved.Extract, response)
+	options.Environment.SetExtracted(result.Variables)
+	return result
+}
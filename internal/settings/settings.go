@@ -0,0 +1,75 @@
+package settings // Package 'settings' stores the application wide defaults in the config directory
+
+import (
+	"encoding/json" // For the file format
+	"errors"        // For detecting a missing settings file
+	"fmt"           // For wrapping errors
+	"io/fs"         // For the not-exist error
+	"os"            // For reading and writing the settings file
+	"path/filepath" // For building the file name
+	"time"          // For the default timeouts
+
+	"github.com/SiirRandall/go-restful/internal/config"                // Config directory lookup
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// FileName is the name of the settings file inside the config directory.
+const FileName = "settings.json"
+
+// 'Settings' holds every application wide default.
+type Settings struct {
+	Client httpclient.ClientSettings `json:"client"` // Defaults for sending requests, requests may override single fields
+}
+
+// Function 'Default' returns the settings used before anything was saved.
+func Default() Settings {
+	return Settings{
+		Client: httpclient.ClientSettings{
+			ConnectTimeout:  httpclient.Duration(10 * time.Second),
+			FollowRedirects: true,
+			MaxRedirects:    httpclient.DefaultMaxRedirects,
+		},
+	}
+}
+
+// Function 'Path' returns the location of the settings file.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Function 'Load' reads the settings file. A missing file gives the defaults without an error.
+func Load() (Settings, error) {
+	s := Default()
+	path, err := Path()
+	if err != nil {
+		return s, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Default(), fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Function 'Save' writes the settings file.
+func Save(s Settings) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,265 @@
+package snippet // Package 'snippet' turns a request into equivalent code in other tools and languages
+
+import (
+	"bytes"         // For building snippets
+	"encoding/json" // For quoting JavaScript strings
+	"fmt"           // For formatted output
+	"sort"          // For a stable header order
+	"strconv"       // For quoting Go and Python strings
+	"strings"       // For string manipulations
+	"time"          // For converting timeouts to seconds
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// 'Generator' produces a snippet for a single request.
+type Generator func(details httpclient.HttpRequestDetails) string
+
+// Languages lists the available snippet targets in the order they should be offered.
+var Languages = []string{
+	"curl",
+	"Go net/http",
+	"Go fasthttp",
+	"Python requests",
+	"JavaScript fetch",
+	"HTTPie",
+}
+
+// generators maps every entry of Languages to its generator.
+var generators = map[string]Generator{
+	"curl":             Curl,
+	"Go net/http":      GoNetHTTP,
+	"Go fasthttp":      GoFasthttp,
+	"Python requests":  PythonRequests,
+	"JavaScript fetch": JavaScriptFetch,
+	"HTTPie":           HTTPie,
+}
+
+// Extensions maps every entry of Languages to the file extension used when saving the snippet.
+var Extensions = map[string]string{
+	"curl":             ".sh",
+	"Go net/http":      ".go",
+	"Go fasthttp":      ".go",
+	"Python requests":  ".py",
+	"JavaScript fetch": ".js",
+	"HTTPie":           ".sh",
+}
+
+// Function 'Generate' returns the snippet for the named language.
+func Generate(language string, details httpclient.HttpRequestDetails) (string, error) {
+	generator, ok := generators[language]
+	if !ok {
+		return "", fmt.Errorf("unknown snippet language %q", language)
+	}
+	if err := details.EncodeBody(); err != nil {
+		return "", err
+	}
+	return generator(details), nil
+}
+
+// Function 'Curl' generates a curl command line. The method is left out where curl picks it on its
+// own, and HEAD uses -I since curl would wait for a body after -X HEAD.
+func Curl(details httpclient.HttpRequestDetails) string {
+	b := &bytes.Buffer{}
+	switch {
+	case details.Method == "HEAD":
+		fmt.Fprintf(b, "curl -I %s", shellQuote(details.URL))
+	case details.Method == "GET" && details.RequestBody == "",
+		details.Method == "POST" && details.RequestBody != "": // --data-raw sends a POST
+		fmt.Fprintf(b, "curl %s", shellQuote(details.URL))
+	default:
+		fmt.Fprintf(b, "curl -X %s %s", details.Method, shellQuote(details.URL))
+	}
+	if details.Digest != nil {
+		fmt.Fprintf(b, " \\\n  --digest -u %s", shellQuote(details.Digest.Username+":"+details.Digest.Password))
+	}
+	settings := details.Settings
+	if settings.FollowRedirects {
+		fmt.Fprintf(b, " \\\n  -L --max-redirs %d", maxRedirects(settings))
+	}
+	if settings.InsecureSkipVerify {
+		b.WriteString(" \\\n  -k")
+	}
+	if settings.ConnectTimeout > 0 {
+		fmt.Fprintf(b, " \\\n  --connect-timeout %g", time.Duration(settings.ConnectTimeout).Seconds())
+	}
+	for _, option := range []struct{ flag, value string }{
+		{"--proxy", settings.Proxy},
+		{"--cacert", settings.CACertFile},
+		{"--cert", settings.ClientCertFile},
+		{"--key", settings.ClientKeyFile},
+	} {
+		if option.value != "" {
+			fmt.Fprintf(b, " \\\n  %s %s", option.flag, shellQuote(option.value))
+		}
+	}
+	for _, key := range sortedKeys(details.Headers) {
+		fmt.Fprintf(b, " \\\n  -H %s", shellQuote(key+": "+details.Headers[key]))
+	}
+	if details.RequestBody != "" {
+		fmt.Fprintf(b, " \\\n  --data-raw %s", shellQuote(details.RequestBody))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Function 'HTTPie' generates an HTTPie command line.
+func HTTPie(details httpclient.HttpRequestDetails) string {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "http %s %s", details.Method, shellQuote(details.URL))
+	if details.Digest != nil {
+		fmt.Fprintf(b, " \\\n  -A digest -a %s", shellQuote(details.Digest.Username+":"+details.Digest.Password))
+	}
+	for _, key := range sortedKeys(details.Headers) {
+		fmt.Fprintf(b, " \\\n  %s", shellQuote(key+":"+details.Headers[key]))
+	}
+	if details.RequestBody != "" {
+		fmt.Fprintf(b, " \\\n  --raw %s", shellQuote(details.RequestBody))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// Function 'GoNetHTTP' generates a Go program using the standard net/http package.
+func GoNetHTTP(details httpclient.HttpRequestDetails) string {
+	b := &bytes.Buffer{}
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	if details.RequestBody != "" {
+		b.WriteString("\t\"strings\"\n")
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+	digestComment(b, details, "\t// ")
+
+	body := "nil"
+	if details.RequestBody != "" {
+		body = fmt.Sprintf("strings.NewReader(%s)", strconv.Quote(details.RequestBody))
+	}
+	fmt.Fprintf(b, "\treq, err := http.NewRequest(%s, %s, %s)\n", strconv.Quote(details.Method), strconv.Quote(details.URL), body)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, key := range sortedKeys(details.Headers) {
+		fmt.Fprintf(b, "\treq.Header.Set(%s, %s)\n", strconv.Quote(key), strconv.Quote(details.Headers[key]))
+	}
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tbody, err := io.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n\tfmt.Println(string(body))\n}\n")
+	return b.String()
+}
+
+// Function 'GoFasthttp' generates a Go program using the fasthttp package.
+func GoFasthttp(details httpclient.HttpRequestDetails) string {
+	b := &bytes.Buffer{}
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/valyala/fasthttp\"\n)\n\nfunc main() {\n")
+	digestComment(b, details, "\t// ")
+	b.WriteString("\treq := fasthttp.AcquireRequest()\n\tresp := fasthttp.AcquireResponse()\n")
+	b.WriteString("\tdefer fasthttp.ReleaseRequest(req)\n\tdefer fasthttp.ReleaseResponse(resp)\n\n")
+	fmt.Fprintf(b, "\treq.SetRequestURI(%s)\n", strconv.Quote(details.URL))
+	fmt.Fprintf(b, "\treq.Header.SetMethod(%s)\n", strconv.Quote(details.Method))
+	for _, key := range sortedKeys(details.Headers) {
+		fmt.Fprintf(b, "\treq.Header.Set(%s, %s)\n", strconv.Quote(key), strconv.Quote(details.Headers[key]))
+	}
+	if details.RequestBody != "" {
+		fmt.Fprintf(b, "\treq.SetBodyString(%s)\n", strconv.Quote(details.RequestBody))
+	}
+	b.WriteString("\n\tif err := fasthttp.Do(req, resp); err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.StatusCode())\n\tfmt.Println(string(resp.Body()))\n}\n")
+	return b.String()
+}
+
+// Function 'PythonRequests' generates a Python script using the requests library.
+func PythonRequests(details httpclient.HttpRequestDetails) string {
+	b := &bytes.Buffer{}
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(b, "url = %s\n", strconv.Quote(details.URL))
+	if len(details.Headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, key := range sortedKeys(details.Headers) {
+			fmt.Fprintf(b, "    %s: %s,\n", strconv.Quote(key), strconv.Quote(details.Headers[key]))
+		}
+		b.WriteString("}\n")
+	}
+	if details.RequestBody != "" {
+		fmt.Fprintf(b, "data = %s\n", strconv.Quote(details.RequestBody))
+	}
+
+	if details.Digest != nil {
+		fmt.Fprintf(b, "auth = requests.auth.HTTPDigestAuth(%s, %s)\n", strconv.Quote(details.Digest.Username), strconv.Quote(details.Digest.Password))
+	}
+
+	fmt.Fprintf(b, "\nresponse = requests.request(%s, url", strconv.Quote(details.Method))
+	if len(details.Headers) > 0 {
+		b.WriteString(", headers=headers")
+	}
+	if details.Digest != nil {
+		b.WriteString(", auth=auth")
+	}
+	if details.RequestBody != "" {
+		b.WriteString(", data=data")
+	}
+	b.WriteString(")\n\nprint(response.status_code)\nprint(response.text)\n")
+	return b.String()
+}
+
+// Function 'JavaScriptFetch' generates a JavaScript snippet using the fetch API.
+func JavaScriptFetch(details httpclient.HttpRequestDetails) string {
+	b := &bytes.Buffer{}
+	digestComment(b, details, "// ")
+	fmt.Fprintf(b, "const response = await fetch(%s, {\n", jsQuote(details.URL))
+	fmt.Fprintf(b, "  method: %s,\n", jsQuote(details.Method))
+	if len(details.Headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, key := range sortedKeys(details.Headers) {
+			fmt.Fprintf(b, "    %s: %s,\n", jsQuote(key), jsQuote(details.Headers[key]))
+		}
+		b.WriteString("  },\n")
+	}
+	if details.RequestBody != "" {
+		fmt.Fprintf(b, "  body: %s,\n", jsQuote(details.RequestBody))
+	}
+	b.WriteString("});\n\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+	return b.String()
+}
+
+// Function 'digestComment' notes in the snippets of clients without Digest auth support that the
+// server's 401 challenge has to be answered by hand.
+func digestComment(b *bytes.Buffer, details httpclient.HttpRequestDetails, prefix string) {
+	if details.Digest == nil {
+		return
+	}
+	fmt.Fprintf(b, "%sDigest auth as %s: answer the WWW-Authenticate challenge of the 401 response\n", prefix, details.Digest.Username)
+	fmt.Fprintf(b, "%swith an Authorization header computed as in RFC 7616 and send the request again.\n", prefix)
+}
+
+// Function 'maxRedirects' returns the hop limit that applies to the settings.
+func maxRedirects(settings httpclient.ClientSettings) int {
+	if settings.MaxRedirects <= 0 {
+		return httpclient.DefaultMaxRedirects
+	}
+	return settings.MaxRedirects
+}
+
+// Function 'shellQuote' wraps a string in single quotes so a POSIX shell passes it through untouched.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Function 'jsQuote' returns a JavaScript string literal for s.
+func jsQuote(s string) string {
+	b := &bytes.Buffer{}
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s) // Encoding a string cannot fail
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Function 'sortedKeys' returns the header names in alphabetical order so snippets are stable.
+func sortedKeys(headers map[string]string) []string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
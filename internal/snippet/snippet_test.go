@@ -0,0 +1,65 @@
+package snippet
+
+import (
+	"strings" // For reading the first line of a snippet
+	"testing" // Go test framework
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// Function 'TestCurlMethod' checks how the method is passed to curl.
+func TestCurlMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		body   string
+		want   string
+	}{
+		{"GET", "", "curl 'https://example.com/'"},
+		{"HEAD", "", "curl -I 'https://example.com/'"},
+		{"POST", `{"a":1}`, "curl 'https://example.com/' \\"},
+		{"POST", "", "curl -X POST 'https://example.com/'"},
+		{"GET", "q", "curl -X GET 'https://example.com/' \\"},
+		{"DELETE", "", "curl -X DELETE 'https://example.com/'"},
+		{"PURGE", "", "curl -X PURGE 'https://example.com/'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.method+" "+test.body, func(t *testing.T) {
+			code := Curl(httpclient.HttpRequestDetails{URL: "https://example.com/", Method: test.method, RequestBody: test.body})
+			if first, _, _ := strings.Cut(code, "\n"); first != test.want {
+				t.Errorf("Curl() starts with %q, want %q", first, test.want)
+			}
+		})
+	}
+}
+
+// Function 'TestDigest' checks that the snippets of tools with Digest auth support use it.
+func TestDigest(t *testing.T) {
+	details := httpclient.HttpRequestDetails{
+		URL:    "https://example.com/",
+		Method: "GET",
+		Digest: &httpclient.Credentials{Username: "ann", Password: "it's"},
+	}
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"curl", `--digest -u 'ann:it'\''s'`},
+		{"HTTPie", `-A digest -a 'ann:it'\''s'`},
+		{"Python requests", `auth = requests.auth.HTTPDigestAuth("ann", "it's")`},
+		{"Go net/http", "// Digest auth as ann"},
+		{"JavaScript fetch", "// Digest auth as ann"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			code, err := Generate(test.language, details)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if !strings.Contains(code, test.want) {
+				t.Errorf("Generate(%q) = %s\nwant it to contain %s", test.language, code, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package text // Package 'text' handles visualizing and formatting text
+
+import (
+	"strings"
+)
+
+// maxDiffCells bounds the size of the table used to diff two texts. Larger inputs are shown
+// as a full replacement instead of a line by line diff.
+const maxDiffCells = 4_000_000
+
+// DiffKind tells whether a diff line is shared, only in the old text or only in the new text.
+type DiffKind int
+
+const (
+	DiffSame    DiffKind = iota // Line present in both texts
+	DiffRemoved                 // Line only in the old text
+	DiffAdded                   // Line only in the new text
+)
+
+// DiffLine is one line of a diff.
+type DiffLine struct {
+	Kind DiffKind
+	Text string
+}
+
+// DiffLines compares two texts line by line and returns the shortest edit between them
+// (longest common subsequence), with removed lines before added ones at every change.
+func DiffLines(before, after string) []DiffLine {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+
+	// Common prefix and suffix are kept out of the table, most diffs only touch a few lines
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []DiffLine
+	for _, line := range a[:prefix] {
+		lines = append(lines, DiffLine{DiffSame, line})
+	}
+	lines = append(lines, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, DiffLine{DiffSame, line})
+	}
+	return lines
+}
+
+// diffMiddle diffs the part of two texts between their common prefix and suffix.
+func diffMiddle(a, b []string) []DiffLine {
+	var lines []DiffLine
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			lines = append(lines, DiffLine{DiffRemoved, line})
+		}
+		for _, line := range b {
+			lines = append(lines, DiffLine{DiffAdded, line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, DiffLine{DiffSame, a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, DiffLine{DiffRemoved, a[i]})
+			i++
+		default:
+			lines = append(lines, DiffLine{DiffAdded, b[j]})
+			j++
+		}
+	}
+	return lines
+}
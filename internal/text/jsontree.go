@@ -0,0 +1,283 @@
+package text // Package 'text' handles visualizing and formatting text
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SiirRandall/go-restful/internal/jsonvalue"
+)
+
+// JSONKind is the type of a JSON value.
+type JSONKind int
+
+const (
+	JSONNull   JSONKind = iota // null
+	JSONBool                   // true or false
+	JSONNumber                 // Any number
+	JSONString                 // A string
+	JSONObject                 // An object, its members are the children
+	JSONArray                  // An array, its items are the children
+)
+
+// JSONNode is a value in a JSON document. Objects and arrays hold their members or items as children
+// and can be collapsed by a viewer.
+type JSONNode struct {
+	Kind      JSONKind
+	Key       string      // Member name, when the parent is an object
+	Index     int         // Position in the parent array, -1 when the parent is not an array
+	Value     string      // Text of a scalar: the string unquoted, the number or true/false/null
+	Children  []*JSONNode // Members or items of an object or array
+	Parent    *JSONNode   // Containing object or array, nil for the root
+	Depth     int         // Number of ancestors
+	Collapsed bool        // Set when a viewer hides the children
+}
+
+// NewJSONTree builds the tree of decoded JSON data, as produced by jsonvalue.Decode or encoding/json.
+// Members of a jsonvalue.Object keep their order, those of a map are sorted by name.
+func NewJSONTree(data interface{}) *JSONNode {
+	return newJSONNode(data, nil, "", -1)
+}
+
+// newJSONNode builds the node of a decoded value and of everything below it.
+func newJSONNode(data interface{}, parent *JSONNode, key string, index int) *JSONNode {
+	node := &JSONNode{Key: key, Index: index, Parent: parent}
+	if parent != nil {
+		node.Depth = parent.Depth + 1
+	}
+
+	switch v := data.(type) {
+	case jsonvalue.Object:
+		node.Kind = JSONObject
+		for _, member := range v {
+			node.Children = append(node.Children, newJSONNode(member.Value, node, member.Key, -1))
+		}
+	case map[string]interface{}:
+		node.Kind = JSONObject
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // Sorting the keys for consistent order
+		for _, key := range keys {
+			node.Children = append(node.Children, newJSONNode(v[key], node, key, -1))
+		}
+	case []interface{}:
+		node.Kind = JSONArray
+		for i, item := range v {
+			node.Children = append(node.Children, newJSONNode(item, node, "", i))
+		}
+	case string:
+		node.Kind, node.Value = JSONString, v
+	case json.Number:
+		node.Kind, node.Value = JSONNumber, v.String() // The number exactly as it was received
+	case float64:
+		node.Kind, node.Value = JSONNumber, strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		node.Kind, node.Value = JSONBool, strconv.FormatBool(v)
+	case nil:
+		node.Kind, node.Value = JSONNull, "null"
+	default:
+		node.Kind, node.Value = JSONString, fmt.Sprintf("%v", v)
+	}
+	return node
+}
+
+// IsContainer reports whether the node is an object or an array.
+func (n *JSONNode) IsContainer() bool {
+	return n.Kind == JSONObject || n.Kind == JSONArray
+}
+
+// Walk calls fn for the node and every node below it, in document order, until fn returns false.
+func (n *JSONNode) Walk(fn func(*JSONNode) bool) bool {
+	if !fn(n) {
+		return false
+	}
+	for _, child := range n.Children {
+		if !child.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of nodes in the tree below and including n.
+func (n *JSONNode) Count() int {
+	count := 0
+	n.Walk(func(*JSONNode) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// Expand shows the children of every ancestor, so the node is visible.
+func (n *JSONNode) Expand() {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		parent.Collapsed = false
+	}
+}
+
+// SetCollapsed collapses or expands every container below and including n, from the given depth on.
+func (n *JSONNode) SetCollapsed(collapsed bool, fromDepth int) {
+	n.Walk(func(node *JSONNode) bool {
+		if node.IsContainer() {
+			node.Collapsed = collapsed && node.Depth >= fromDepth
+		}
+		return true
+	})
+}
+
+// Path returns the JSONPath of the node, such as $.data[3].id. Names that are not plain identifiers
+// are quoted: $['content-type'].
+func (n *JSONNode) Path() string {
+	if n.Parent == nil {
+		return "$"
+	}
+	if n.Parent.Kind == JSONArray {
+		return fmt.Sprintf("%s[%d]", n.Parent.Path(), n.Index)
+	}
+	if isIdentifier(n.Key) {
+		return n.Parent.Path() + "." + n.Key
+	}
+	return fmt.Sprintf("%s['%s']", n.Parent.Path(), strings.ReplaceAll(n.Key, "'", "\\'"))
+}
+
+// Find returns the node at a path below n. The path is a JSONPath without wildcards, such as
+// $.data[3].id or $['content-type']; the leading $ and the brackets around indexes may be left
+// out, as in data.3.id.
+func (n *JSONNode) Find(path string) (*JSONNode, error) {
+	segments, err := ParseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	node := n
+	for _, segment := range segments {
+		next := node.child(segment)
+		if next == nil {
+			return nil, fmt.Errorf("%s has no %q", node.Path(), segment)
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// child returns the member or item of n named by a path segment, nil when there is none.
+func (n *JSONNode) child(segment string) *JSONNode {
+	switch n.Kind {
+	case JSONObject:
+		for _, child := range n.Children {
+			if child.Key == segment {
+				return child
+			}
+		}
+	case JSONArray:
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(n.Children) {
+			return n.Children[index]
+		}
+	}
+	return nil
+}
+
+// ParseJSONPath splits a path such as $.data[3]['content-type'] into its member names and indexes.
+func ParseJSONPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+
+	var segments []string
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+		case '[':
+			start := len(path) - len(strings.TrimLeft(path[1:], " \t")) // First character after the blanks
+			if start < len(path) && (path[start] == '\'' || path[start] == '"') {
+				// A quoted name, blanks may surround it as in $[ 'a' ]
+				quote := path[start]
+				end := closingQuote(path, start)
+				if end < 0 {
+					return nil, fmt.Errorf("missing closing quote in path")
+				}
+				segments = append(segments, strings.ReplaceAll(path[start+1:end], "\\"+string(quote), string(quote)))
+				path = strings.TrimLeft(path[end+1:], " \t")
+				if !strings.HasPrefix(path, "]") {
+					return nil, fmt.Errorf("missing ] in path")
+				}
+				path = path[1:]
+				continue
+			}
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in path")
+			}
+			segments = append(segments, strings.TrimSpace(path[1:end]))
+			path = path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segments = append(segments, path[:end])
+			path = path[end:]
+		}
+	}
+	return segments, nil
+}
+
+// closingQuote returns the position of the quote ending the quoted name whose opening quote is at
+// path[start], -1 when there is none.
+func closingQuote(path string, start int) int {
+	quote := path[start]
+	for i := start + 1; i < len(path); i++ {
+		switch path[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// isIdentifier reports whether a member name can be written after a dot in a path.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// QuoteJSONString returns a string as a JSON string literal, with control characters escaped.
+func QuoteJSONString(s string) string {
+	b := &strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
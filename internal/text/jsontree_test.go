@@ -0,0 +1,40 @@
+package text
+
+import (
+	"reflect" // For comparing segment lists
+	"testing" // Go test framework
+)
+
+// TestParseJSONPath checks the path forms jump-to-path and Find accept.
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "$", want: nil},
+		{path: "$.data[3].id", want: []string{"data", "3", "id"}},
+		{path: "data.3.id", want: []string{"data", "3", "id"}},
+		{path: "$['content-type']", want: []string{"content-type"}},
+		{path: `$["content-type"]`, want: []string{"content-type"}},
+		{path: "$[ 'a' ]", want: []string{"a"}},
+		{path: "$[\t\"a\"\t][ 2 ]", want: []string{"a", "2"}},
+		{path: "$['a]b'].c", want: []string{"a]b", "c"}},
+		{path: `$['it\'s']`, want: []string{"it's"}},
+		{path: "$[ 'a' x]", wantErr: true},
+		{path: "$['a", wantErr: true},
+		{path: "$[3", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := ParseJSONPath(test.path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseJSONPath(%q) error = %v, want error: %v", test.path, err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ParseJSONPath(%q) = %q, want %q", test.path, got, test.want)
+			}
+		})
+	}
+}
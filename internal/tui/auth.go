@@ -0,0 +1,221 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/auth"       // Authentication schemes module
+	"github.com/SiirRandall/go-restful/internal/collection" // Saved requests module
+	"github.com/SiirRandall/go-restful/internal/oauth2"     // OAuth 2.0 token module
+)
+
+// secretMask is the character shown instead of secret values while they are hidden.
+const secretMask = '*'
+
+// AuthEditor is the Token page: a scheme dropdown, a toggle revealing secrets and the inputs of the
+// selected scheme. Values typed for one scheme are kept while switching to another and back.
+type AuthEditor struct {
+	*tview.Form
+
+	auth        auth.Auth           // Every value typed so far, whatever scheme it belongs to
+	showSecrets bool                // Secret inputs show their value instead of the mask
+	secrets     []*tview.InputField // Inputs of the current scheme that hold secrets
+	building    bool                // Set while the items are rebuilt, dropdown callbacks are ignored
+	changed     func()              // Called after the scheme or a credential was edited
+}
+
+// Fixed items of the Token page, the inputs of the scheme follow them
+const (
+	authSchemeItem = iota
+	authSecretsItem
+	authFieldsStart
+)
+
+// InitTokenForm initializes the Token page, with no scheme selected
+func InitTokenForm() *AuthEditor {
+	editor := &AuthEditor{Form: tview.NewForm()}
+
+	labels := make([]string, len(auth.Types))
+	for i, name := range auth.Types {
+		scheme, _ := auth.SchemeFor(name)
+		labels[i] = scheme.Label()
+	}
+	editor.AddDropDown("Scheme", labels, 0, func(_ string, index int) {
+		if editor.building || index < 0 || auth.Types[index] == editor.auth.Type {
+			return
+		}
+		editor.auth.Type = auth.Types[index]
+		editor.buildFields()
+		editor.notify()
+	})
+	editor.AddCheckbox("Show secrets", false, func(checked bool) {
+		editor.showSecrets = checked
+		for _, input := range editor.secrets {
+			input.SetMaskCharacter(editor.mask())
+		}
+	})
+	editor.SetBorder(true). // Set a border around the Token form
+				SetTitle("[white]Params - Headers - Body - [green]Token [white]- Tests") // Set the title of the Token form
+
+	return editor
+}
+
+// SetChangedFunc sets a handler called whenever the scheme or a credential is edited.
+func (e *AuthEditor) SetChangedFunc(handler func()) {
+	e.changed = handler
+}
+
+// notify calls the changed handler, unless the items are being rebuilt.
+func (e *AuthEditor) notify() {
+	if e.changed != nil && !e.building {
+		e.changed()
+	}
+}
+
+// Auth returns the credentials of the selected scheme, nil when no scheme is selected.
+// Values typed for other schemes are left out.
+func (e *AuthEditor) Auth() *auth.Auth {
+	scheme, err := auth.SchemeFor(e.auth.Type)
+	if err != nil || e.auth.Type == auth.TypeNone {
+		return nil
+	}
+	selected := &auth.Auth{Type: e.auth.Type}
+	for _, field := range scheme.Fields() {
+		*field.Value(selected) = *field.Value(&e.auth)
+	}
+	return selected
+}
+
+// Load shows the credentials of a request, nil selects no scheme.
+func (e *AuthEditor) Load(credentials *auth.Auth) {
+	e.auth = auth.Auth{}
+	if credentials != nil {
+		e.auth = *credentials
+	}
+
+	index := 0
+	for i, name := range auth.Types {
+		if name == e.auth.Type {
+			index = i
+		}
+	}
+	e.building = true
+	e.GetFormItem(authSchemeItem).(*tview.DropDown).SetCurrentOption(index)
+	e.building = false
+	e.auth.Type = auth.Types[index] // Unknown schemes fall back to none
+	e.buildFields()
+}
+
+// buildFields replaces the inputs after the fixed items with those of the selected scheme.
+func (e *AuthEditor) buildFields() {
+	e.building = true
+	defer func() { e.building = false }()
+
+	for e.GetFormItemCount() > authFieldsStart {
+		e.RemoveFormItem(e.GetFormItemCount() - 1)
+	}
+	e.secrets = nil
+
+	scheme, err := auth.SchemeFor(e.auth.Type)
+	if err != nil {
+		return
+	}
+	for _, field := range scheme.Fields() {
+		value := field.Value(&e.auth)
+		if len(field.Options) > 0 {
+			index := 0
+			for i, option := range field.Options {
+				if option == *value {
+					index = i
+				}
+			}
+			*value = field.Options[index] // An empty value means the first option
+			e.AddDropDown(field.Label, field.Options, index, func(option string, _ int) {
+				*value = option
+				e.notify()
+			})
+			continue
+		}
+
+		input := tview.NewInputField().SetLabel(field.Label).SetText(*value).SetFieldWidth(50)
+		input.SetChangedFunc(func(text string) {
+			*value = text
+			e.notify()
+		})
+		if field.Secret {
+			input.SetMaskCharacter(e.mask())
+			e.secrets = append(e.secrets, input)
+		}
+		e.AddFormItem(input)
+	}
+}
+
+// mask returns the mask character for secret inputs, 0 when secrets are shown.
+func (e *AuthEditor) mask() rune {
+	if e.showSecrets {
+		return 0
+	}
+	return secretMask
+}
+
+// prepareAuth fetches what the auth scheme of request needs, such as an OAuth2 token, in the
+// background and calls then on the UI goroutine once it succeeded. Like a request it shows a spinner
+// and can be cancelled with CancelRequest.
+func prepareAuth(app *tview.Application, forms *RequestForms, request *collection.Request, then func()) {
+	session := forms.Session
+	logView, detailsView := forms.Log, forms.Details
+	scheme, err := auth.SchemeFor(request.Auth.Type)
+	if err != nil {
+		LogMessage(logView, err.Error())
+		return
+	}
+	summary := fmt.Sprintf("Fetching credentials for %s", scheme.Label())
+	if request.Auth.Type == auth.TypeOAuth2 && request.Auth.Grant == oauth2.GrantAuthorizationCode {
+		summary += "\nFinish the login in the browser, the authorization URL is also in the log"
+	}
+
+	// The browser is opened from the background goroutine, the URL is logged in case it does not open
+	authorize := func(authURL string) {
+		openErr := oauth2.OpenBrowser(authURL)
+		app.QueueUpdateDraw(func() {
+			LogMessage(logView, fmt.Sprintf("Open this URL to authorize: %s", authURL))
+			if openErr != nil {
+				LogMessage(logView, fmt.Sprintf("Could not open a browser: %v", openErr))
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	preparing := &inFlight{cancel: cancel}
+	session.inFlight = preparing
+	setSendLabel(forms, "Cancel")
+	detailsView.SetText(summary)
+
+	settings := session.Settings.Client.Apply(request.Settings)
+	go runSpinner(ctx, app, detailsView, summary)
+	go func() {
+		err := request.Auth.Prepare(ctx, settings, authorize)
+		cancelled := ctx.Err() != nil
+		cancel() // Stops the spinner
+		app.QueueUpdateDraw(func() {
+			if session.inFlight == preparing {
+				session.inFlight = nil
+				setSendLabel(forms, "Send")
+			}
+			switch {
+			case cancelled:
+				LogMessage(logView, "Cancelled while fetching credentials")
+				detailsView.SetText(fmt.Sprintf("%s\n\n[red]Cancelled[white]", summary))
+			case err != nil:
+				LogMessage(logView, fmt.Sprintf("Error fetching credentials: %v", err))
+				detailsView.SetText(fmt.Sprintf("%s\n\n[red]%s[white]", summary, tview.Escape(err.Error())))
+			default:
+				LogMessage(logView, fmt.Sprintf("Credentials for %s fetched", scheme.Label()))
+				then()
+			}
+		})
+	}()
+}
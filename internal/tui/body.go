@@ -0,0 +1,225 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/collection"            // Saved requests module
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// bodyModeLabels names the body modes in the mode dropdown, in the order of httpclient.BodyModes.
+var bodyModeLabels = map[string]string{
+	httpclient.BodyNone:       "None",
+	httpclient.BodyRaw:        "Raw",
+	httpclient.BodyURLEncoded: "Form urlencoded",
+	httpclient.BodyMultipart:  "Multipart form",
+	httpclient.BodyBinary:     "Binary file",
+}
+
+// rawContentTypes are offered for raw bodies. The empty type leaves the Content-Type to the headers.
+var rawContentTypes = []struct{ label, value string }{
+	{"JSON", "application/json"},
+	{"XML", "application/xml"},
+	{"Text", "text/plain"},
+	{"HTML", "text/html"},
+	{"From headers", ""},
+}
+
+// multipartFieldTypes are the kinds of multipart fields, a file field holds a path.
+var multipartFieldTypes = []string{"Text", "File"}
+
+// BodyEditor is the Body page: a mode dropdown followed by the editor of that mode. Values typed
+// for one mode are kept while switching to another and back.
+type BodyEditor struct {
+	*tview.Form
+
+	body     collection.Body // Every value typed so far, whatever mode it belongs to
+	building bool            // Set while the items are rebuilt, dropdown callbacks are ignored
+	changed  func()          // Called after any value of the body was edited
+}
+
+// InitBodyForm initializes the Body page, with a raw JSON body selected
+func InitBodyForm() *BodyEditor {
+	editor := &BodyEditor{Form: tview.NewForm()}
+	editor.body = collection.Body{Mode: httpclient.BodyRaw, ContentType: rawContentTypes[0].value}
+
+	labels := make([]string, len(httpclient.BodyModes))
+	for i, mode := range httpclient.BodyModes {
+		labels[i] = bodyModeLabels[mode]
+	}
+	editor.AddDropDown("Mode", labels, 1, func(_ string, index int) {
+		if editor.building || index < 0 || httpclient.BodyModes[index] == editor.body.Mode {
+			return
+		}
+		editor.body.Mode = httpclient.BodyModes[index]
+		editor.buildFields()
+		editor.notify()
+	})
+	editor.buildFields()
+	editor.SetBorder(true). // Set border around the Body form
+				SetTitle("[white]Params - Headers - [green]Body [white]- Token - Tests") // Set the title of the Body form
+
+	return editor
+}
+
+// SetChangedFunc sets a handler called whenever the mode or a value of the body is edited.
+func (e *BodyEditor) SetChangedFunc(handler func()) {
+	e.changed = handler
+}
+
+// notify calls the changed handler, unless the items are being rebuilt.
+func (e *BodyEditor) notify() {
+	if e.changed != nil && !e.building {
+		e.changed()
+	}
+}
+
+// Body returns the body of the selected mode. Values typed for other modes and fields without a
+// key are left out.
+func (e *BodyEditor) Body() collection.Body {
+	body := collection.Body{Mode: e.body.Mode}
+	switch e.body.Mode {
+	case httpclient.BodyRaw:
+		body.Raw, body.ContentType = e.body.Raw, e.body.ContentType
+	case httpclient.BodyURLEncoded, httpclient.BodyMultipart:
+		for _, field := range e.body.Form {
+			if strings.TrimSpace(field.Key) == "" {
+				continue
+			}
+			field.Key = strings.TrimSpace(field.Key)
+			field.File = field.File && e.body.Mode == httpclient.BodyMultipart
+			body.Form = append(body.Form, field)
+		}
+	case httpclient.BodyBinary:
+		body.File = e.body.File
+	}
+	return body
+}
+
+// Load shows the body of a request.
+func (e *BodyEditor) Load(body collection.Body) {
+	e.body = body
+	e.body.Form = append([]collection.KeyValue(nil), body.Form...)
+
+	index := 0
+	for i, mode := range httpclient.BodyModes {
+		if mode == e.body.Mode {
+			index = i
+		}
+	}
+	e.building = true
+	e.GetFormItem(0).(*tview.DropDown).SetCurrentOption(index)
+	e.building = false
+	e.body.Mode = httpclient.BodyModes[index] // Unknown modes fall back to none
+	e.buildFields()
+}
+
+// buildFields replaces everything after the mode dropdown with the editor of the selected mode.
+func (e *BodyEditor) buildFields() {
+	e.building = true
+	defer func() { e.building = false }()
+
+	for e.GetFormItemCount() > 1 {
+		e.RemoveFormItem(e.GetFormItemCount() - 1)
+	}
+	e.ClearButtons()
+
+	switch e.body.Mode {
+	case httpclient.BodyRaw:
+		// Offer the stored type even when it is none of the usual ones
+		options := make([]string, 0, len(rawContentTypes)+1)
+		index := -1
+		for i, contentType := range rawContentTypes {
+			options = append(options, contentType.label)
+			if contentType.value == e.body.ContentType {
+				index = i
+			}
+		}
+		if index < 0 {
+			options = append(options, e.body.ContentType)
+			index = len(options) - 1
+		}
+		e.AddDropDown("Content type", options, index, func(option string, index int) {
+			if index < len(rawContentTypes) {
+				e.body.ContentType = rawContentTypes[index].value
+			} else {
+				e.body.ContentType = option
+			}
+			e.notify()
+		})
+		e.AddTextArea("Body", e.body.Raw, 50, 10, 0, func(text string) {
+			e.body.Raw = text
+			e.notify()
+		})
+	case httpclient.BodyURLEncoded, httpclient.BodyMultipart:
+		if len(e.body.Form) == 0 {
+			e.body.Form = append(e.body.Form, collection.KeyValue{})
+		}
+		for i := range e.body.Form {
+			e.addFieldRow(i)
+		}
+		e.AddButton("Add Field", func() {
+			e.body.Form = append(e.body.Form, collection.KeyValue{})
+			e.addFieldRow(len(e.body.Form) - 1)
+		})
+	case httpclient.BodyBinary:
+		e.AddInputField("File", e.body.File, 50, nil, func(text string) {
+			e.body.File = text
+			e.notify()
+		})
+	}
+}
+
+// addFieldRow adds the inputs of form field i. Multipart fields get a Text/File choice as well.
+func (e *BodyEditor) addFieldRow(i int) {
+	field := e.body.Form[i]
+	multipartRow := e.body.Mode == httpclient.BodyMultipart
+	valueLabel := "└Value"
+	if multipartRow {
+		valueLabel = "│Value"
+	}
+
+	e.AddInputField(fmt.Sprintf("┌Key %d", i+1), field.Key, 50, nil, func(text string) {
+		e.body.Form[i].Key = text
+		e.notify()
+	})
+	e.AddInputField(valueLabel, field.Value, 50, nil, func(text string) {
+		e.body.Form[i].Value = text
+		e.notify()
+	})
+	if multipartRow {
+		fieldType := 0
+		if field.File {
+			fieldType = 1
+		}
+		e.AddDropDown("└Type", multipartFieldTypes, fieldType, func(_ string, index int) {
+			e.body.Form[i].File = index == 1
+			e.notify()
+		})
+	}
+}
+
+// describeBody summarizes a body in plain text: the raw text, the form fields or the file sent.
+func describeBody(body collection.Body) string {
+	switch body.Mode {
+	case httpclient.BodyRaw:
+		return body.Raw
+	case httpclient.BodyURLEncoded, httpclient.BodyMultipart:
+		lines := []string{bodyModeLabels[body.Mode]}
+		for _, field := range body.Form {
+			if field.File {
+				lines = append(lines, fmt.Sprintf("%s: file %s", field.Key, field.Value))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s=%s", field.Key, field.Value))
+			}
+		}
+		return strings.Join(lines, "\n")
+	case httpclient.BodyBinary:
+		return "File " + body.File
+	}
+	return ""
+}
@@ -0,0 +1,336 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2" // External library used for handling terminal cell views
+	"github.com/rivo/tview"       // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/collection" // Saved requests module
+)
+
+// collectionNode is the reference stored on every node of the collections tree.
+type collectionNode struct {
+	collection *collection.Collection // Collection the node belongs to
+	parent     *collection.Folder     // Folder that contains the node (nil for collections)
+	folder     *collection.Folder     // Set for collection and folder nodes
+	request    *collection.Request    // Set for request nodes
+}
+
+// CollectionsBrowser is the sidebar tree used to browse, open and organize saved requests.
+type CollectionsBrowser struct {
+	Tree        *tview.TreeView
+	pages       *tview.Pages
+	forms       *RequestForms
+	collections []*collection.Collection
+	expanded    map[*collection.Folder]bool // Folders the user has opened
+	current     *collectionNode             // Request currently loaded into the editor, if any
+}
+
+// InitCollectionsBrowser builds the collections sidebar and loads every saved collection from disk.
+func InitCollectionsBrowser(pages *tview.Pages, forms *RequestForms) *CollectionsBrowser {
+	b := &CollectionsBrowser{
+		Tree:     tview.NewTreeView(),
+		pages:    pages,
+		forms:    forms,
+		expanded: make(map[*collection.Folder]bool),
+	}
+	b.Tree.SetBorder(true).SetTitle("Collections")
+	b.Tree.SetSelectedFunc(b.selected)
+
+	// Shortcuts that act on the highlighted node
+	b.Tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		node := b.currentNode()
+		switch {
+		case event.Rune() == 'n':
+			b.newCollection()
+		case event.Rune() == 'f' && node != nil:
+			b.newFolder(node)
+		case event.Rune() == 'r' && node != nil:
+			b.rename(node)
+		case event.Rune() == 'd' && node != nil:
+			b.duplicate(node)
+		case (event.Rune() == 'x' || event.Key() == tcell.KeyDelete) && node != nil:
+			b.delete(node)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	b.Reload()
+	return b
+}
+
+// Reload reads all collections from disk again and redraws the tree.
+func (b *CollectionsBrowser) Reload() {
+	collections, err := collection.LoadAll()
+	if err != nil {
+		LogMessage(b.forms.Log, fmt.Sprintf("Error loading collections: %v", err))
+	}
+	b.collections = collections
+	b.current = nil
+	b.rebuild()
+}
+
+// rebuild recreates the tree nodes from the loaded collections.
+func (b *CollectionsBrowser) rebuild() {
+	root := tview.NewTreeNode("Collections").SetSelectable(false)
+	for _, c := range b.collections {
+		root.AddChild(b.folderNode(c, nil, &c.Folder, "[yellow]"))
+	}
+	if len(b.collections) == 0 {
+		root.AddChild(tview.NewTreeNode("[gray]n: new collection, Ctrl+S: save request").SetSelectable(false))
+	}
+	b.Tree.SetRoot(root).SetTopLevel(1)
+
+	// Keep the cursor on a real node so the shortcuts work straight away
+	if b.Tree.GetCurrentNode() == nil && len(root.GetChildren()) > 0 {
+		b.Tree.SetCurrentNode(root.GetChildren()[0])
+	}
+}
+
+// folderNode creates the tree node for a collection or folder along with all of its children.
+func (b *CollectionsBrowser) folderNode(
+	c *collection.Collection,
+	parent, folder *collection.Folder,
+	color string,
+) *tview.TreeNode {
+	node := tview.NewTreeNode(color + tview.Escape(folder.Name)).
+		SetReference(&collectionNode{collection: c, parent: parent, folder: folder}).
+		SetExpanded(b.expanded[folder])
+
+	for _, sub := range folder.Folders {
+		node.AddChild(b.folderNode(c, folder, sub, "[green]"))
+	}
+	for _, request := range folder.Requests {
+		label := fmt.Sprintf("[blue]%s[white] %s", request.Method, tview.Escape(request.Name))
+		node.AddChild(tview.NewTreeNode(label).
+			SetReference(&collectionNode{collection: c, parent: folder, request: request}))
+	}
+	return node
+}
+
+// currentNode returns the reference of the highlighted tree node.
+func (b *CollectionsBrowser) currentNode() *collectionNode {
+	node := b.Tree.GetCurrentNode()
+	if node == nil {
+		return nil
+	}
+	ref, _ := node.GetReference().(*collectionNode)
+	return ref
+}
+
+// selected opens requests and expands or collapses folders when Enter is pressed.
+func (b *CollectionsBrowser) selected(node *tview.TreeNode) {
+	ref, ok := node.GetReference().(*collectionNode)
+	if !ok {
+		return
+	}
+	if ref.request != nil {
+		b.current = ref
+		b.forms.Load(ref.request)
+		LogMessage(b.forms.Log, fmt.Sprintf("Opened %s / %s", ref.collection.Name, ref.request.Name))
+		return
+	}
+	node.SetExpanded(!node.IsExpanded())
+	b.expanded[ref.folder] = node.IsExpanded()
+}
+
+// save writes a collection to disk and redraws the tree.
+func (b *CollectionsBrowser) save(c *collection.Collection) {
+	if err := c.Save(); err != nil {
+		LogMessage(b.forms.Log, fmt.Sprintf("Error saving collection %s: %v", c.Name, err))
+	}
+	b.rebuild()
+}
+
+// newCollection asks for a name and creates an empty collection file.
+func (b *CollectionsBrowser) newCollection() {
+	Prompt(b.pages, "New collection", "Name", "", func(name string) {
+		if strings.TrimSpace(name) == "" {
+			return
+		}
+		c, err := collection.New(name)
+		if err != nil {
+			LogMessage(b.forms.Log, fmt.Sprintf("Error creating collection: %v", err))
+			return
+		}
+		b.collections = append(b.collections, c)
+		b.save(c)
+	})
+}
+
+// newFolder asks for a name and adds a folder below the highlighted collection or folder.
+func (b *CollectionsBrowser) newFolder(ref *collectionNode) {
+	parent := ref.folder
+	if parent == nil { // A request is highlighted, create the folder next to it
+		parent = ref.parent
+	}
+	Prompt(b.pages, "New folder", "Name", "", func(name string) {
+		if strings.TrimSpace(name) == "" {
+			return
+		}
+		parent.FolderByPath(name, true)
+		b.expanded[parent] = true
+		b.save(ref.collection)
+	})
+}
+
+// rename asks for a new name for the highlighted collection, folder or request.
+func (b *CollectionsBrowser) rename(ref *collectionNode) {
+	current := ref.folder
+	name := ""
+	if ref.request != nil {
+		name = ref.request.Name
+	} else {
+		name = current.Name
+	}
+	Prompt(b.pages, "Rename", "Name", name, func(newName string) {
+		if strings.TrimSpace(newName) == "" {
+			return
+		}
+		if ref.request != nil {
+			ref.request.Name = newName
+		} else {
+			current.Name = newName
+		}
+		b.save(ref.collection)
+	})
+}
+
+// duplicate copies the highlighted request within its folder.
+func (b *CollectionsBrowser) duplicate(ref *collectionNode) {
+	if ref.request == nil {
+		return
+	}
+	ref.parent.DuplicateRequest(ref.request)
+	b.save(ref.collection)
+}
+
+// delete removes the highlighted node after asking for confirmation.
+func (b *CollectionsBrowser) delete(ref *collectionNode) {
+	var question string
+	switch {
+	case ref.request != nil:
+		question = fmt.Sprintf("Delete request %q?", ref.request.Name)
+	case ref.parent == nil:
+		question = fmt.Sprintf("Delete collection %q and its file?", ref.collection.Name)
+	default:
+		question = fmt.Sprintf("Delete folder %q and everything in it?", ref.folder.Name)
+	}
+
+	Confirm(b.pages, question, func() {
+		if b.current != nil && (b.current.request == ref.request || b.current.collection == ref.collection && ref.parent == nil) {
+			b.current = nil
+		}
+
+		switch {
+		case ref.request != nil:
+			ref.parent.RemoveRequest(ref.request)
+		case ref.parent != nil:
+			ref.parent.RemoveFolder(ref.folder)
+		default:
+			if err := ref.collection.Delete(); err != nil {
+				LogMessage(b.forms.Log, fmt.Sprintf("Error deleting collection: %v", err))
+				return
+			}
+			for i, c := range b.collections {
+				if c == ref.collection {
+					b.collections = append(b.collections[:i], b.collections[i+1:]...)
+					break
+				}
+			}
+			b.rebuild()
+			return
+		}
+		b.save(ref.collection)
+	})
+}
+
+// SaveCurrent stores the editor contents back into the request it was opened from.
+// Requests that were never saved are handed to SaveAs.
+func (b *CollectionsBrowser) SaveCurrent() {
+	if b.current == nil {
+		b.SaveAs()
+		return
+	}
+	request := b.forms.Request()
+	request.Name = b.current.request.Name
+	*b.current.request = *request
+	b.save(b.current.collection)
+	LogMessage(b.forms.Log, fmt.Sprintf("Saved %s / %s", b.current.collection.Name, request.Name))
+}
+
+// SaveAs asks for a name and a "collection/folder" path and saves the editor contents there.
+// Collections and folders that do not exist yet are created.
+func (b *CollectionsBrowser) SaveAs() {
+	location := ""
+	if ref := b.currentNode(); ref != nil {
+		location = ref.collection.Name
+	}
+
+	form := tview.NewForm().
+		AddInputField("Name", "", 40, nil, nil).
+		AddInputField("Collection/folder", location, 40, nil, nil)
+	form.AddButton("Save", func() {
+		name := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		path := form.GetFormItem(1).(*tview.InputField).GetText()
+		HideModal(b.pages, "save")
+		if name == "" {
+			return
+		}
+		b.saveTo(name, path)
+	})
+	form.AddButton("Cancel", func() {
+		HideModal(b.pages, "save")
+	})
+	form.SetCancelFunc(func() {
+		HideModal(b.pages, "save")
+	})
+	form.SetBorder(true).SetTitle("Save request")
+
+	ShowModal(b.pages, "save", form, 70, 9)
+}
+
+// saveTo adds the editor contents as a new request at "collection/folder/..." and saves the collection.
+func (b *CollectionsBrowser) saveTo(name, path string) {
+	parts := strings.SplitN(strings.Trim(path, "/ "), "/", 2)
+	collectionName := strings.TrimSpace(parts[0])
+	if collectionName == "" {
+		collectionName = "Default"
+	}
+
+	var target *collection.Collection
+	for _, c := range b.collections {
+		if c.Name == collectionName {
+			target = c
+			break
+		}
+	}
+	if target == nil {
+		c, err := collection.New(collectionName)
+		if err != nil {
+			LogMessage(b.forms.Log, fmt.Sprintf("Error creating collection: %v", err))
+			return
+		}
+		target = c
+		b.collections = append(b.collections, c)
+	}
+
+	folder := &target.Folder
+	if len(parts) == 2 {
+		folder = target.FolderByPath(parts[1], true)
+	}
+
+	request := b.forms.Request()
+	request.Name = name
+	folder.AddRequest(request)
+	b.expanded[&target.Folder] = true
+	b.expanded[folder] = true
+	b.current = &collectionNode{collection: target, parent: folder, request: request}
+	b.save(target)
+	LogMessage(b.forms.Log, fmt.Sprintf("Saved %s / %s", target.Name, name))
+}
@@ -2,68 +2,157 @@ package tui // Package 'tui' for handling text UI tasks
 
 import (
 	"fmt"
+	"strings"
 
 	tview "github.com/rivo/tview" // External library used for terminal-based user interfaces
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
 )
 
-// InitUrlComponents initializes the UI components for URL input and action buttons (Send, Quit)
-func InitUrlComponents(
-	app *tview.Application,
-	urlForm *tview.Form,
-	detailsForm *tview.Form,
-	logView *tview.TextView,
-	textView *ScrollTextView,
-	detailsView *tview.TextView,
-) (*tview.Form, *tview.Form) {
-	// Dropdown for selecting the HTTP method
-	methodbox := tview.NewForm().AddDropDown("", []string{"GET", "POST", "PUT", "DELETE"}, 0, nil)
+// customMethodOption is the last entry of the method dropdown, it asks for a free-text verb
+const customMethodOption = "Custom..."
+
+// MethodBox is the method dropdown of the top bar: the standard methods, then the custom verbs
+// entered or loaded so far, then the option asking for a new one.
+type MethodBox struct {
+	*tview.Form
+
+	dropDown *tview.DropDown
+	methods  []string // Options before the custom one, httpclient.StandardMethods first
+}
+
+// NewMethodBox creates the method dropdown with the standard methods, GET selected.
+func NewMethodBox() *MethodBox {
+	box := &MethodBox{
+		Form:    tview.NewForm(),
+		methods: append([]string(nil), httpclient.StandardMethods...),
+	}
+	box.AddDropDown("", box.options(), 0, nil)
+	box.dropDown = box.GetFormItem(0).(*tview.DropDown)
+	return box
+}
+
+// Method returns the selected method.
+func (b *MethodBox) Method() string {
+	_, method := b.dropDown.GetCurrentOption()
+	return method
+}
+
+// Select selects a method, appending it as a new option if it is missing.
+func (b *MethodBox) Select(method string) {
+	for i, option := range b.methods {
+		if option == method {
+			b.dropDown.SetCurrentOption(i)
+			return
+		}
+	}
+	b.methods = append(b.methods, method)
+	b.dropDown.SetOptions(b.options(), nil)
+	b.dropDown.SetCurrentOption(len(b.methods) - 1)
+}
 
-	// Panel of action buttons - Send and Quit
+// options returns the options of the dropdown, the custom one last.
+func (b *MethodBox) options() []string {
+	return append(append([]string(nil), b.methods...), customMethodOption)
+}
+
+// InitUrlComponents initializes the UI components for URL input and action buttons (Send, Quit).
+// The method dropdown it creates is also stored in forms, picking a method updates the request.
+func InitUrlComponents(app *tview.Application, pages *tview.Pages, forms *RequestForms) (*MethodBox, *tview.Form) {
+	// Dropdown for selecting the HTTP method, the last option lets the user type any verb
+	methodbox := NewMethodBox()
+	forms.Method = methodbox
+
+	dropDown := methodbox.dropDown
+	previous := 0 // Option to return to when entering a custom verb is cancelled
+	dropDown.SetSelectedFunc(func(text string, index int) {
+		if text != customMethodOption {
+			previous = index
+			forms.update()
+			return
+		}
+		dropDown.SetCurrentOption(previous)
+		Prompt(pages, "Custom method", "Method", "", func(method string) {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if !httpclient.ValidMethod(method) {
+				LogMessage(forms.Log, fmt.Sprintf("Invalid method %q", method))
+				return
+			}
+			methodbox.Select(method)
+		})
+	})
+
+	// Panel of action buttons - Send (Cancel while a request is in flight) and Quit
 	buttonPanel := tview.NewForm().
 		AddButton("Send", func() {
-			SendAction(urlForm, detailsForm, logView, textView, detailsView, methodbox) // Define the function to be called when 'Send' is clicked
-			_, method := methodbox.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
-			LogMessage(logView, fmt.Sprintf("Method: %s", method)) // Log the selected method when 'Send' is clicked
+			if forms.Session.Sending() {
+				CancelRequest(forms) // The Send button reads Cancel while a request is in flight
+				return
+			}
+			SendAction(app, forms)                                                         // Define the function to be called when 'Send' is clicked
+			LogMessage(forms.Log, fmt.Sprintf("Method: %s", forms.Session.Request.Method)) // Log the selected method when 'Send' is clicked
 		}).
 		AddButton("Quit", func() {
 			app.Stop() // Define the function to be called when 'Quit' is clicked
 		})
 
+	forms.Buttons = buttonPanel
+
 	return methodbox, buttonPanel
 }
 
-// InitUrlandButtons initializes the container for method selection, URL input, and action buttons
+// InitUrlandButtons initializes the container for method and environment selection, URL input, and action buttons
 func InitUrlandButtons(
 	methodbox *tview.Form,
+	envbox *tview.Form,
 	urlForm *tview.Form,
 	buttonPanel *tview.Form,
 ) *tview.Flex {
 	// Create a flexible layout container ("Flex") and add the elements to it.
 	urlAndButtons := tview.NewFlex().
-		AddItem(methodbox, 9, 1, false).  // Add the methodbox to the Flex
+		AddItem(methodbox, 12, 1, false). // Add the methodbox to the Flex
+		AddItem(envbox, 20, 1, false).    // Add the environment dropdown to the Flex
 		AddItem(urlForm, 0, 4, true).     // Add the urlForm to the Flex
 		AddItem(buttonPanel, 0, 1, false) // Add the buttonPanel to the Flex
 
 	return urlAndButtons
 }
 
-// InitGrid initializes the main grid that includes urlAndButtons, htmlPages, textView, and detailsForm
+// InitGrid initializes the main grid that includes urlAndButtons, htmlPages, textView, and detailsView
 func InitGrid(
 	urlAndButtons *tview.Flex,
 	htmlPages *tview.Pages,
-	textView *ScrollTextView,
-	detailsForm *tview.Form,
+	textView *ResponseViewer,
+	detailsView *tview.TextView,
 ) *tview.Flex {
 	// Set up a new Flex grid that arranges its added items in rows
 	grid := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(urlAndButtons, 3, 1, true). // Add the urlAndButtons to the Flex grid
 		AddItem(tview.NewFlex().
-			AddItem(htmlPages, 35, 1, false). // Add another flex that contains htmlPages, textView, detailsForm
+			AddItem(htmlPages, 45, 1, false). // Add another flex that contains htmlPages, textView, detailsView
 			AddItem(textView, 0, 1, false).
-			AddItem(detailsForm, 30, 1, false),
+			AddItem(detailsView, 40, 1, false),
 			0, 1, false,
 		)
 
 	return grid
 }
+
+// InitMainLayout places the (initially hidden) collections sidebar to the left of the main grid
+func InitMainLayout(sidebar tview.Primitive, grid *tview.Flex) *tview.Flex {
+	return tview.NewFlex().
+		AddItem(sidebar, 0, 0, false). // Zero width until toggled
+		AddItem(grid, 0, 1, true)
+}
+
+// ToggleSidebar shows or hides the sidebar in the main layout, focusing it when shown
+func ToggleSidebar(app *tview.Application, layout *tview.Flex, sidebar tview.Primitive, width int) {
+	if sidebar.HasFocus() {
+		layout.ResizeItem(sidebar, 0, 0)
+		app.SetFocus(layout.GetItem(1))
+		return
+	}
+	layout.ResizeItem(sidebar, width, 0)
+	app.SetFocus(sidebar)
+}
@@ -0,0 +1,245 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2" // External library used for handling terminal cell views
+	"github.com/rivo/tview"       // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/cookies" // Cookie jars of the environments
+)
+
+// cookieTimeLayout is how expiry times are shown and typed in the cookies dialog.
+const cookieTimeLayout = "2006-01-02 15:04:05"
+
+// CookieJar returns the cookie jar of the active environment, requests are sent with it.
+func (f *RequestForms) CookieJar() *cookies.Jar {
+	jar, err := cookies.For(f.Session.EnvironmentName())
+	if err != nil {
+		LogMessage(f.Log, fmt.Sprintf("Error loading cookies: %v", err))
+	}
+	return jar
+}
+
+// ShowCookiesModal opens the cookie jar of the active environment. Enter edits the highlighted
+// cookie, 'a' adds one, 'x' deletes one and 'C' clears the jar.
+func ShowCookiesModal(app *tview.Application, pages *tview.Pages, forms *RequestForms) {
+	jar := forms.CookieJar()
+	jarName := forms.Session.EnvironmentName()
+	if jarName == "" {
+		jarName = noEnvironment
+	}
+
+	table := tview.NewTable().SetSelectable(true, false).SetFixed(1, 0)
+	table.SetBorder(true)
+	var list []cookies.Cookie
+
+	// fill rebuilds the table from the jar, keeping the selected row when it still exists
+	fill := func() {
+		list = jar.List()
+		table.Clear()
+		for column, title := range []string{"Name", "Value", "Domain", "Path", "Expires", "Flags"} {
+			table.SetCell(0, column, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+		}
+		for i, cookie := range list {
+			row := i + 1
+			table.SetCell(row, 0, tview.NewTableCell(tview.Escape(cookie.Name)))
+			table.SetCell(row, 1, tview.NewTableCell(tview.Escape(shorten(cookie.Value, 40))).SetExpansion(1))
+			table.SetCell(row, 2, tview.NewTableCell(tview.Escape(cookieDomain(cookie))))
+			table.SetCell(row, 3, tview.NewTableCell(tview.Escape(cookie.Path)))
+			table.SetCell(row, 4, tview.NewTableCell(cookieExpiry(cookie)))
+			table.SetCell(row, 5, tview.NewTableCell(cookieFlags(cookie)))
+		}
+		if len(list) == 0 {
+			table.SetCell(1, 1, tview.NewTableCell("[gray]No cookies yet, responses that set cookies fill the jar[-]").SetSelectable(false))
+		}
+		table.SetTitle(fmt.Sprintf("Cookies - %s (%d)", tview.Escape(jarName), len(list)))
+		if row, _ := table.GetSelection(); row > len(list) {
+			table.Select(max(len(list), 1), 0)
+		}
+	}
+	// current returns the highlighted cookie
+	current := func() *cookies.Cookie {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(list) {
+			return nil
+		}
+		return &list[row-1]
+	}
+
+	table.SetSelectedFunc(func(int, int) {
+		if cookie := current(); cookie != nil {
+			showCookieForm(app, pages, forms, jar, *cookie, false, func() { fill(); app.SetFocus(table) })
+		}
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		cookie := current()
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			HideModal(pages, "cookies")
+		case event.Rune() == 'a':
+			showCookieForm(app, pages, forms, jar, cookies.Cookie{Path: "/"}, true, func() { fill(); app.SetFocus(table) })
+		case (event.Rune() == 'x' || event.Key() == tcell.KeyDelete) && cookie != nil:
+			Confirm(pages, fmt.Sprintf("Delete the cookie %s of %s?", cookie.Name, cookie.Domain), func() {
+				if err := jar.Delete(*cookie); err != nil {
+					LogMessage(forms.Log, fmt.Sprintf("Error deleting cookie: %v", err))
+				}
+				fill()
+				app.SetFocus(table)
+			})
+		case event.Rune() == 'C':
+			Confirm(pages, fmt.Sprintf("Clear every cookie of %s?", jarName), func() {
+				if err := jar.Clear(); err != nil {
+					LogMessage(forms.Log, fmt.Sprintf("Error clearing cookies: %v", err))
+				}
+				fill()
+				app.SetFocus(table)
+			})
+		default:
+			return event
+		}
+		return nil
+	})
+
+	fill()
+	table.Select(1, 0)
+
+	help := tview.NewTextView().SetDynamicColors(true).
+		SetText("[yellow]Enter[white] edit  [yellow]a[white] add  [yellow]x[white] delete  [yellow]C[white] clear  [yellow]Esc[white] close")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	layout.SetBorder(true).SetTitle("Cookie jar")
+
+	ShowModal(pages, "cookies", layout, 140, 30)
+	app.SetFocus(table)
+}
+
+// showCookieForm edits a cookie of the jar, or a new one when adding, and calls done once it was
+// saved or the form was cancelled.
+func showCookieForm(
+	app *tview.Application,
+	pages *tview.Pages,
+	forms *RequestForms,
+	jar *cookies.Jar,
+	cookie cookies.Cookie,
+	adding bool,
+	done func(),
+) {
+	form := tview.NewForm()
+	expires := ""
+	if !cookie.Expires.IsZero() {
+		expires = cookie.Expires.Local().Format(cookieTimeLayout)
+	}
+	form.AddInputField("Name", cookie.Name, 50, nil, nil)
+	form.AddInputField("Value", cookie.Value, 50, nil, nil)
+	form.AddInputField("Domain", cookie.Domain, 50, nil, nil)
+	form.AddInputField("Path", cookie.Path, 50, nil, nil)
+	form.AddInputField("Expires", expires, 50, nil, nil)
+	form.AddCheckbox("Host only", cookie.HostOnly || adding, nil)
+	form.AddCheckbox("Secure", cookie.Secure, nil)
+	form.AddCheckbox("HttpOnly", cookie.HttpOnly, nil)
+	message := tview.NewTextView().SetDynamicColors(true).
+		SetText("[gray]Expires as YYYY-MM-DD HH:MM:SS, or a duration such as 2h; empty for a session cookie[-]")
+	message.SetSize(2, 0)
+	form.AddFormItem(message)
+
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+	checked := func(label string) bool {
+		return form.GetFormItemByLabel(label).(*tview.Checkbox).IsChecked()
+	}
+	finish := func() {
+		HideModal(pages, "cookie")
+		done()
+	}
+
+	form.AddButton("Save", func() {
+		edited := cookies.Cookie{
+			Name:     text("Name"),
+			Value:    form.GetFormItemByLabel("Value").(*tview.InputField).GetText(),
+			Domain:   text("Domain"),
+			Path:     text("Path"),
+			HostOnly: checked("Host only"),
+			Secure:   checked("Secure"),
+			HttpOnly: checked("HttpOnly"),
+		}
+		expiry, err := parseCookieExpiry(text("Expires"))
+		switch {
+		case edited.Name == "" || edited.Domain == "":
+			err = fmt.Errorf("a cookie needs a name and a domain")
+		case err == nil:
+			edited.Expires = expiry
+		}
+		if err != nil {
+			message.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+			return
+		}
+		previous := cookie
+		if adding {
+			previous = edited
+		}
+		if err := jar.Put(previous, edited); err != nil {
+			LogMessage(forms.Log, fmt.Sprintf("Error saving cookie: %v", err))
+		}
+		finish()
+	})
+	form.AddButton("Cancel", finish)
+	form.SetCancelFunc(finish)
+	title := "Edit cookie"
+	if adding {
+		title = "Add cookie"
+	}
+	form.SetBorder(true).SetTitle(title)
+
+	ShowModal(pages, "cookie", form, 70, 27)
+	app.SetFocus(form)
+}
+
+// parseCookieExpiry reads the expiry typed in the cookie form: a local time, a duration from now,
+// or nothing for a session cookie.
+func parseCookieExpiry(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(text); err == nil {
+		return time.Now().Add(d), nil
+	}
+	expiry, err := time.ParseInLocation(cookieTimeLayout, text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expires %q is not a time such as 2030-01-31 12:00:00 or a duration such as 2h", text)
+	}
+	return expiry, nil
+}
+
+// cookieDomain shows the domain of a cookie, with a leading dot when its subdomains get it too.
+func cookieDomain(cookie cookies.Cookie) string {
+	if cookie.HostOnly {
+		return cookie.Domain
+	}
+	return "." + cookie.Domain
+}
+
+// cookieExpiry shows when a cookie expires, or Session for a session cookie.
+func cookieExpiry(cookie cookies.Cookie) string {
+	if cookie.Expires.IsZero() {
+		return "Session"
+	}
+	return cookie.Expires.Local().Format(cookieTimeLayout)
+}
+
+// cookieFlags lists the Secure and HttpOnly flags of a cookie.
+func cookieFlags(cookie cookies.Cookie) string {
+	var flags []string
+	if cookie.Secure {
+		flags = append(flags, "Secure")
+	}
+	if cookie.HttpOnly {
+		flags = append(flags, "HttpOnly")
+	}
+	return strings.Join(flags, " ")
+}
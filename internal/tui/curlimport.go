@@ -0,0 +1,38 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/curl" // curl command line parser
+)
+
+// ShowCurlImportModal opens a dialog where a curl command can be pasted. On import the command
+// replaces the method, URL, params, headers and body of the request being edited.
+func ShowCurlImportModal(app *tview.Application, pages *tview.Pages, forms *RequestForms) {
+	form := tview.NewForm()
+	form.AddTextArea("", "", 0, 12, 0, nil)
+	form.AddButton("Import", func() {
+		command := form.GetFormItem(0).(*tview.TextArea).GetText()
+		request, err := curl.Parse(command)
+		if err != nil {
+			LogMessage(forms.Log, fmt.Sprintf("Error importing curl command: %v", err))
+			return
+		}
+		HideModal(pages, "curl")
+		forms.Load(request)
+		LogMessage(forms.Log, fmt.Sprintf("Imported %s %s", request.Method, request.URL))
+	})
+	form.AddButton("Cancel", func() {
+		HideModal(pages, "curl")
+	})
+	form.SetCancelFunc(func() {
+		HideModal(pages, "curl")
+	})
+	form.SetBorder(true).SetTitle("Import curl command (paste below)")
+
+	ShowModal(pages, "curl", form, 100, 18)
+	app.SetFocus(form)
+}
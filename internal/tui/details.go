@@ -0,0 +1,157 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/assertion"             // Checks of the response
+	"github.com/SiirRandall/go-restful/internal/extract"               // Values saved from the response into variables
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// RenderResponseDetails writes the status line, the results of the request's checks, the variables
+// set from the response, the timing breakdown and the response headers into the details view.
+func RenderResponseDetails(
+	detailsView *tview.TextView,
+	response httpclient.HttpResponseDetails,
+	results []assertion.Result,
+	extracted []extract.Result,
+) {
+	b := &strings.Builder{}
+
+	// Status line, colored by status class
+	fmt.Fprintf(b, "[%s::b]%d %s[-::-]\n", statusColor(response.StatusCode), response.StatusCode, response.StatusText)
+	fmt.Fprintf(b, "[blue]Protocol:[white] %s\n", response.Protocol)
+	fmt.Fprintf(b, "[blue]Remote:[white] %s\n", response.RemoteAddr)
+	fmt.Fprintf(b, "[blue]Size:[white] %s", formatSize(response.Size))
+	if response.Encoding != "" {
+		fmt.Fprintf(b, ", %s %s on the wire", formatSize(response.WireSize), tview.Escape(response.Encoding))
+	}
+	if response.ContentLength >= 0 {
+		fmt.Fprintf(b, " (Content-Length %d)", response.ContentLength)
+	}
+	b.WriteString("\n")
+	if response.Charset != "" {
+		fmt.Fprintf(b, "[blue]Charset:[white] %s, converted to UTF-8\n", tview.Escape(response.Charset))
+	}
+	if response.DecodeError != nil {
+		fmt.Fprintf(b, "[red]%s, the body is shown as received[white]\n", tview.Escape(response.DecodeError.Error()))
+	}
+	if len(response.Redirects) > 0 {
+		fmt.Fprintf(b, "[blue]Redirects:[white] %d\n", len(response.Redirects))
+		fmt.Fprintf(b, "[blue]Final URL:[white] %s\n", tview.Escape(response.URL))
+	}
+	b.WriteString("\n")
+
+	// Checks of the Tests page, failing ones with what was found instead
+	if len(results) > 0 {
+		writeTestResults(b, results)
+		b.WriteString("\n")
+	}
+	if len(extracted) > 0 {
+		writeExtracted(b, extracted)
+		b.WriteString("\n")
+	}
+
+	// Timing breakdown
+	b.WriteString("[yellow::b]Timing[-::-]\n")
+	fmt.Fprintf(b, "[blue]DNS:[white]        %s\n", formatDuration(response.Timing.DNS))
+	fmt.Fprintf(b, "[blue]Connect:[white]    %s\n", formatDuration(response.Timing.Connect))
+	fmt.Fprintf(b, "[blue]TLS:[white]        %s\n", formatDuration(response.Timing.TLS))
+	fmt.Fprintf(b, "[blue]First byte:[white] %s\n", formatDuration(response.Timing.FirstByte))
+	fmt.Fprintf(b, "[blue]Total:[white]      %s\n\n", formatDuration(response.Timing.Total))
+
+	// Every response header, repeated ones included
+	fmt.Fprintf(b, "[yellow::b]Headers (%d)[-::-]\n", len(response.Headers))
+	for _, header := range response.Headers {
+		fmt.Fprintf(b, "[blue]%s:[white] %s\n", tview.Escape(header.Key), tview.Escape(header.Value))
+	}
+
+	detailsView.SetText(b.String())
+	detailsView.ScrollToBeginning()
+}
+
+// writeTestResults writes a line per check, passed or failed, with the expected and actual value of
+// those that failed.
+func writeTestResults(b *strings.Builder, results []assertion.Result) {
+	passed := assertion.Passed(results)
+	color := "green"
+	if passed < len(results) {
+		color = "red"
+	}
+	fmt.Fprintf(b, "[yellow::b]Tests[-::-] [%s]%d/%d passed[white]\n", color, passed, len(results))
+	for _, result := range results {
+		if result.Passed {
+			fmt.Fprintf(b, "[green]✓[white] %s\n", tview.Escape(result.Assertion.String()))
+			continue
+		}
+		fmt.Fprintf(b, "[red]✗[white] %s\n", tview.Escape(result.Assertion.String()))
+		if result.Error != "" {
+			fmt.Fprintf(b, "    [red]%s[white]\n", tview.Escape(result.Error))
+		} else {
+			fmt.Fprintf(b, "    [blue]actual:[white] %s\n", tview.Escape(shorten(result.Actual, 60)))
+		}
+	}
+}
+
+// writeExtracted writes a line per variable set from the response with its new value, or why it
+// was not set.
+func writeExtracted(b *strings.Builder, extracted []extract.Result) {
+	b.WriteString("[yellow::b]Variables[-::-]\n")
+	for _, result := range extracted {
+		if result.Error != "" {
+			fmt.Fprintf(b, "[red]✗[white] %s\n    [red]%s[white]\n", tview.Escape(result.Rule.Variable), tview.Escape(result.Error))
+			continue
+		}
+		fmt.Fprintf(b, "[green]✓[white] %s = %s\n", tview.Escape(result.Rule.Variable), tview.Escape(shorten(result.Value, 40)))
+	}
+}
+
+// shorten puts text on one line and cuts it to at most max runes.
+func shorten(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return text
+}
+
+// statusColor returns the tview color name used for a status code class.
+func statusColor(code int) string {
+	switch {
+	case code >= 500:
+		return "red"
+	case code >= 400:
+		return "orange"
+	case code >= 300:
+		return "yellow"
+	case code >= 200:
+		return "green"
+	default:
+		return "white"
+	}
+}
+
+// formatSize formats a byte count as B, KB or MB.
+func formatSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// formatDuration rounds a duration so it stays readable in the narrow details panel.
+func formatDuration(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Microsecond * 10).String()
+}
@@ -0,0 +1,197 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2" // External library used for handling terminal cell views
+	"github.com/rivo/tview"       // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/environment" // Environments and variables module
+)
+
+// noEnvironment is the dropdown option used when no environment is active.
+const noEnvironment = "No environment"
+
+// EnvironmentBox is the environment dropdown shown in the top bar next to the method dropdown.
+type EnvironmentBox struct {
+	Form         *tview.Form
+	forms        *RequestForms
+	environments []*environment.Environment
+}
+
+// InitEnvironmentBox builds the environment dropdown and loads every saved environment.
+func InitEnvironmentBox(forms *RequestForms) *EnvironmentBox {
+	box := &EnvironmentBox{
+		Form:  tview.NewForm(),
+		forms: forms,
+	}
+	box.Form.AddDropDown("", []string{noEnvironment}, 0, nil)
+	box.Reload("")
+	return box
+}
+
+// Reload reads the environments from disk again and selects the named one (or none).
+func (b *EnvironmentBox) Reload(selected string) {
+	environments, err := environment.LoadAll()
+	if err != nil {
+		LogMessage(b.forms.Log, fmt.Sprintf("Error loading environments: %v", err))
+	}
+	b.environments = environments
+
+	options := []string{noEnvironment}
+	current := 0
+	for i, e := range environments {
+		options = append(options, e.Name)
+		if e.Name == selected {
+			current = i + 1
+		}
+	}
+
+	dropDown := b.Form.GetFormItem(0).(*tview.DropDown)
+	dropDown.SetOptions(options, func(_ string, index int) {
+		if index <= 0 {
+			b.forms.Session.Environment = nil
+			return
+		}
+		b.forms.Session.Environment = b.environments[index-1]
+	})
+	dropDown.SetCurrentOption(current)
+}
+
+// Active returns the name of the selected environment, or "" when none is selected.
+func (b *EnvironmentBox) Active() string {
+	return b.forms.Session.EnvironmentName()
+}
+
+// ShowEnvironmentsModal opens the environment editor. Environments are listed on the left and the
+// variables of the highlighted one can be edited on the right.
+func ShowEnvironmentsModal(app *tview.Application, pages *tview.Pages, box *EnvironmentBox) {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle("Environments")
+
+	nameForm := tview.NewForm().AddInputField("Name", "", 40, nil, nil)
+	variablesForm := tview.NewForm()
+	variablesForm.AddButton("Add Variable", func() { AddKeyValueFields(variablesForm) })
+	variablesForm.SetBorder(true).SetTitle("Variables - use as {{name}}")
+
+	var editing *environment.Environment // Environment shown in the editor, nil for a new one
+
+	// show fills the editor with an environment
+	show := func(e *environment.Environment) {
+		editing = e
+		variablesForm.Clear(false)
+		name := ""
+		if e != nil {
+			name = e.Name
+			SetFormKeyValues(variablesForm, e.Variables, func() { AddKeyValueFields(variablesForm) })
+		}
+		if len(formInputFields(variablesForm)) == 0 {
+			AddKeyValueFields(variablesForm)
+		}
+		nameForm.GetFormItem(0).(*tview.InputField).SetText(name)
+	}
+
+	// refresh rebuilds the list and shows the environment with the given name
+	refresh := func(selected string) {
+		box.Reload(box.Active())
+		list.Clear()
+		current := 0
+		for i, e := range box.environments {
+			e := e
+			list.AddItem(e.Name, "", 0, func() { show(e); app.SetFocus(variablesForm) })
+			if e.Name == selected {
+				current = i
+			}
+		}
+		if len(box.environments) == 0 {
+			show(nil)
+			return
+		}
+		list.SetCurrentItem(current)
+		show(box.environments[current])
+	}
+	// Tab moves from the list to the name and from the name to the variables and buttons
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab:
+			app.SetFocus(nameForm)
+			return nil
+		case tcell.KeyEscape:
+			HideModal(pages, "environments")
+			return nil
+		}
+		return event
+	})
+	nameForm.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab || event.Key() == tcell.KeyEnter {
+			app.SetFocus(variablesForm)
+			return nil
+		}
+		return event
+	})
+	list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		if index >= 0 && index < len(box.environments) {
+			show(box.environments[index])
+		}
+	})
+
+	variablesForm.
+		AddButton("New", func() {
+			show(nil)
+			app.SetFocus(nameForm)
+		}).
+		AddButton("Save", func() {
+			name := strings.TrimSpace(nameForm.GetFormItem(0).(*tview.InputField).GetText())
+			if name == "" {
+				LogMessage(box.forms.Log, "Environment needs a name")
+				return
+			}
+			e := editing
+			if e == nil {
+				var err error
+				if e, err = environment.New(name); err != nil {
+					LogMessage(box.forms.Log, fmt.Sprintf("Error creating environment: %v", err))
+					return
+				}
+			}
+			e.Name = name
+			e.Variables = FormKeyValues(variablesForm)
+			if err := e.Save(); err != nil {
+				LogMessage(box.forms.Log, fmt.Sprintf("Error saving environment: %v", err))
+				return
+			}
+			LogMessage(box.forms.Log, fmt.Sprintf("Saved environment %s", name))
+			refresh(name)
+		}).
+		AddButton("Delete", func() {
+			if editing == nil {
+				return
+			}
+			e := editing
+			Confirm(pages, fmt.Sprintf("Delete environment %q?", e.Name), func() {
+				if err := e.Delete(); err != nil {
+					LogMessage(box.forms.Log, fmt.Sprintf("Error deleting environment: %v", err))
+				}
+				refresh("")
+			})
+		}).
+		AddButton("Close", func() {
+			HideModal(pages, "environments")
+		})
+	nameForm.SetCancelFunc(func() { HideModal(pages, "environments") })
+	variablesForm.SetCancelFunc(func() { HideModal(pages, "environments") })
+
+	editor := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nameForm, 3, 0, false).
+		AddItem(variablesForm, 0, 1, false)
+	layout := tview.NewFlex().
+		AddItem(list, 25, 0, true).
+		AddItem(editor, 0, 1, false)
+	layout.SetBorder(true).SetTitle("Environments (Esc to close)")
+
+	refresh(box.Active())
+	ShowModal(pages, "environments", layout, 100, 30)
+	app.SetFocus(list)
+}
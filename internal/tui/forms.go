@@ -2,123 +2,96 @@ package tui // Package 'tui' for handling text UI tasks
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/rivo/tview" // External library used for terminal-based user interfaces
-)
 
-var (
-	paramsIndex = 1 // Index used to track params form items
+	"github.com/SiirRandall/go-restful/internal/collection" // Saved requests module
 )
 
-// InitBodyForm initializes the form for inputting Body data
-func InitBodyForm() *tview.Form {
-	bodyForm := tview.NewForm().
-		AddTextArea("Body", "", 50, 10, 0, func(text string) {}). // Add a textarea field for Body
-		AddInputField("┌Key: ", "", 50, nil, nil).                // Add fields for Key and Value
-		AddInputField("└Value", "", 50, nil, nil)
-	bodyForm.SetBorder(true). // Set border around the Body form
-					SetTitle("[white]Params - Headers - [green]Body [white]- Token") // Set the title of the Body form
+// HeadersEditor is the Headers page: key/value rows with auto-completion of the usual header names
+// and of their values.
+type HeadersEditor struct {
+	*tview.Form
 
-	return bodyForm
+	changed func() // Called after a key or a value was edited
 }
 
-// InitTokenForm initializes the form for inputting Token data
-func InitTokenForm() *tview.Form {
-	tokenForm := tview.NewForm().
-		AddInputField("┌Key", "", 50, nil, nil). // Add fields for Key and Value
-		AddInputField("└Value", "", 50, nil, nil)
-	tokenForm.SetBorder(true). // Set a border around the Token form
-					SetTitle("[white]Params - Headers - Body - [green]Token") // Set the title of the Token form
+// InitHeadersForm initializes the form for inputting Headers data
+func InitHeadersForm() *HeadersEditor {
+	editor := &HeadersEditor{Form: tview.NewForm()}
+	// Add initial fields for Key and Value
+	editor.addRow("┌Key:")
+	// Add a button to add more headers dynamically, each new pair gets the same auto-complete as the first one
+	editor.AddButton("Add More Headers", editor.AddHeaderFields)
 
-	return tokenForm
+	editor.SetBorder(true). // Set a border around the Headers form
+				SetTitle("[white]Params - [green]Headers [white]- Body - Token - Tests") // Set the title of the Headers form
+
+	return editor
 }
 
-// InitHeadersForm initializes the form for inputting Headers data
-func InitHeadersForm(logView *tview.TextView) *tview.Form {
-	headersForm := tview.NewForm()
-	// Add initial fields for Key and Value
-	headersForm.AddInputField("┌Key:", "", 50, nil, nil)
-	headersForm.AddInputField("└Value", "", 50, nil, nil)
-	// Add a button to add more headers dynamically and to handle deletion of headers
-	// This contains quite a bit of logic for managing the form state correctly
-	headersForm.AddButton("Add More Headers", func() {
-		// Omitted for brevity...
-	})
+// SetChangedFunc sets a handler called whenever a key or a value is edited.
+func (e *HeadersEditor) SetChangedFunc(handler func()) {
+	e.changed = handler
+}
 
-	headersForm.SetBorder(true). // Set a border around the Headers form
-					SetTitle("[white]Params - [green]Headers [white]- Body - Token") // Set the title of the Headers form
+// Headers returns the rows with a key, in order.
+func (e *HeadersEditor) Headers() []collection.KeyValue {
+	return FormKeyValues(e.Form)
+}
 
-	// Auto-complete functionality for headers keys and values
-	keyInput := headersForm.GetFormItem(0).(*tview.InputField)
-	valueInput := headersForm.GetFormItem(1).(*tview.InputField)
+// Load shows the headers of a request, adding rows as needed.
+func (e *HeadersEditor) Load(headers []collection.KeyValue) {
+	SetFormKeyValues(e.Form, headers, e.AddHeaderFields)
+}
+
+// AddHeaderFields appends another key/value row with header auto-completion to the Headers page
+func (e *HeadersEditor) AddHeaderFields() {
+	e.addRow(fmt.Sprintf("┌Key %d:", len(formInputFields(e.Form))/2+1))
+}
 
+// addRow adds a key and a value input, with auto-completion, labelled keyLabel and Value.
+func (e *HeadersEditor) addRow(keyLabel string) {
+	notify := func(string) {
+		if e.changed != nil {
+			e.changed()
+		}
+	}
+	keyInput := tview.NewInputField().SetLabel(keyLabel).SetFieldWidth(50).SetChangedFunc(notify)
+	valueInput := tview.NewInputField().SetLabel("└Value").SetFieldWidth(50).SetChangedFunc(notify)
 	SetAutoCompleteForHeaders(keyInput)
 	SetAutoCompleteForValues(valueInput, keyInput)
-
-	return headersForm
+	e.AddFormItem(keyInput).AddFormItem(valueInput)
 }
 
-// InitParamsForm initializes the form for inputting Params data
+// InitParamsForm connects the Params page to the URL input and adds its first row
 func InitParamsForm(
-	paramsForm *tview.Form,
+	params *ParamsEditor,
 	urlForm *tview.Form,
-	logView *tview.TextView,
-) *tview.Form {
-	paramsForm.
-		// Add initial fields for Key and Value
-		// The UpdateURLWithParams function is called every time a key or value changes
-		AddInputField("┌Key ", "", 50, func(textToCheck string, lastChar rune) bool {
-			UpdateURLWithParams(urlForm, paramsForm, logView)
-			test1, test2 := urlForm.GetFocusedItemIndex()
-			LogMessage(logView, fmt.Sprintf("Url focus: %d, %d", test1, test2))
-			return true
-		}, nil).
-		AddInputField("└Value ", "", 50, func(textToCheck string, lastChar rune) bool {
-			UpdateURLWithParams(urlForm, paramsForm, logView)
-			return true
-		}, nil).
-		AddButton("Add More Params", func() {
-			paramsIndex++
-			AddKeyValueFieldsToForm(paramsForm, urlForm, paramsIndex, logView)
-		})
-	paramsForm.SetBorder(true).SetTitle("[green]Params [white]- Headers - Body - Token")
-
-	return paramsForm
-}
-
-// InitDetailsForm initializes the form for displaying Request Details
-func InitDetailsForm() *tview.Form {
-	detailsForm := tview.NewForm()
-
-	detailsForm.SetBorder(true).SetTitle("Request Details") // Set a border and title for the Details form
+) *ParamsEditor {
+	params.url = urlForm.GetFormItem(0).(*tview.InputField)
+	params.buildRows()
+	// Add a button to add more params, an empty row does not change the URL until it gets a key
+	params.AddButton("Add More Params", func() {
+		params.rows = append(params.rows, collection.KeyValue{})
+		params.addRow(len(params.rows) - 1)
+	})
+	params.SetBorder(true).SetTitle("[green]Params [white]- Headers - Body - Token - Tests")
 
-	return detailsForm
+	return params
 }
 
 // InitURLForm initializes the form for inputting URL data
-func InitURLForm(
-	app *tview.Application,
-	paramsForm *tview.Form,
-	logView *tview.TextView,
-) *tview.Form {
+func InitURLForm(params *ParamsEditor) *tview.Form {
 	urlForm := tview.NewForm().
 		AddInputField("URL", "https://jsonplaceholder.typicode.com/posts", 100, nil, nil) // Add an InputField for the URL
 	urlForm.SetBorder(false).SetTitle("URL")
 
-	// Get a reference to the URL InputField. A change in this should trigger an update in paramsForm.
+	// Get a reference to the URL InputField. A change in this rebuilds the Params page, unless the
+	// change was made by the Params page itself.
 	urlInput := urlForm.GetFormItem(0).(*tview.InputField)
 	urlInput.SetChangedFunc(func(text string) {
-		urlFocus, _ := urlForm.GetFocusedItemIndex()
-		LogMessage(logView, fmt.Sprintf("Url focus: %d", urlFocus))
-		go func() {
-			time.Sleep(time.Millisecond * 50) // Short delay to avoid clashes with other updates
-			app.QueueUpdateDraw(func() {
-				if urlFocus == 0 {
-					UpdateParamsFromURL(urlForm, paramsForm, logView)
-				}
-			})
-		}()
+		params.UpdateParamsFromURL()
 	})
 	return urlForm
 }
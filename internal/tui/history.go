@@ -0,0 +1,230 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2" // External library used for handling terminal cell views
+	"github.com/rivo/tview"       // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/history"               // Request history module
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+	"github.com/SiirRandall/go-restful/internal/text"                  // Text utilities module
+)
+
+// ShowHistoryModal opens the history of sent requests. Enter re-opens an entry into the editor and
+// shows its response, 'd' diffs it against the response currently shown, 'x' deletes it and 'C'
+// clears the whole history.
+func ShowHistoryModal(
+	app *tview.Application,
+	pages *tview.Pages,
+	forms *RequestForms,
+) {
+	session, detailsView := forms.Session, forms.Details
+	entries, err := history.Load()
+	if err != nil {
+		LogMessage(forms.Log, fmt.Sprintf("Error loading history: %v", err))
+	}
+
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(fmt.Sprintf("History (%d)", len(entries)))
+	preview := tview.NewTextView().SetDynamicColors(true).SetScrollable(true)
+	preview.SetBorder(true)
+
+	// fill rebuilds the list from entries
+	fill := func() {
+		list.Clear()
+		for _, entry := range entries {
+			list.AddItem(historyLine(entry), "", 0, nil)
+		}
+		list.SetTitle(fmt.Sprintf("History (%d)", len(entries)))
+		if len(entries) == 0 {
+			preview.SetText("No requests sent yet").SetTitle("")
+		}
+	}
+	// current returns the highlighted entry
+	current := func() *history.Entry {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(entries) {
+			return nil
+		}
+		return entries[index]
+	}
+
+	list.SetChangedFunc(func(index int, _, _ string, _ rune) {
+		if index >= 0 && index < len(entries) {
+			preview.SetText(historyPreview(entries[index])).ScrollToBeginning()
+			preview.SetTitle("Entry")
+		}
+	})
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		entry := entries[index]
+		HideModal(pages, "history")
+		forms.Load(entry.Request)
+		if entry.Response.Error != "" {
+			detailsView.SetText(fmt.Sprintf("[red]%s[white]", tview.Escape(entry.Response.Error)))
+			session.response = nil
+		} else {
+			response := entry.Response.HttpResponseDetails()
+			detailsView.SetText("")
+			assertions := session.Environment.Apply(entry.Request).Assertions // The checks of the request as it was sent
+			showResponse(forms, response, assertions, nil)
+			session.response = &response
+		}
+		LogMessage(forms.Log, fmt.Sprintf("Opened history entry from %s", entry.Time.Format("2006-01-02 15:04:05")))
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		entry := current()
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			HideModal(pages, "history")
+		case event.Key() == tcell.KeyTab:
+			app.SetFocus(preview)
+		case event.Rune() == 'd' && entry != nil:
+			if session.response == nil {
+				preview.SetText("[yellow]No response to compare with, send a request first[white]").SetTitle("Diff")
+				return nil
+			}
+			// Both sides are redacted like the history, so secret headers neither show up nor differ
+			stored, current := entry.Response.HttpResponseDetails(), *session.response
+			stored.Headers, current.Headers = history.RedactHeaders(stored.Headers), history.RedactHeaders(current.Headers)
+			preview.SetText(diffText(responseText(stored), responseText(current))).ScrollToBeginning()
+			preview.SetTitle("Diff: [red]- this entry [green]+ current response[white]")
+		case (event.Rune() == 'x' || event.Key() == tcell.KeyDelete) && entry != nil:
+			Confirm(pages, "Delete this history entry?", func() {
+				if err := history.Delete(entry); err != nil {
+					LogMessage(forms.Log, fmt.Sprintf("Error deleting history entry: %v", err))
+					return
+				}
+				index := list.GetCurrentItem()
+				entries = append(entries[:index], entries[index+1:]...)
+				fill()
+				app.SetFocus(list)
+			})
+		case event.Rune() == 'C':
+			Confirm(pages, "Clear the whole history?", func() {
+				if err := history.Clear(); err != nil {
+					LogMessage(forms.Log, fmt.Sprintf("Error clearing history: %v", err))
+					return
+				}
+				entries = nil
+				fill()
+				app.SetFocus(list)
+			})
+		default:
+			return event
+		}
+		return nil
+	})
+	preview.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape, tcell.KeyTab:
+			app.SetFocus(list)
+			return nil
+		}
+		return event
+	})
+
+	fill()
+	if len(entries) > 0 {
+		preview.SetText(historyPreview(entries[0])).SetTitle("Entry")
+	}
+
+	help := tview.NewTextView().SetDynamicColors(true).
+		SetText("[yellow]Enter[white] open  [yellow]d[white] diff with current response  [yellow]x[white] delete  [yellow]C[white] clear  [yellow]Tab[white] scroll preview  [yellow]Esc[white] close")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			AddItem(list, 0, 1, true).
+			AddItem(preview, 0, 1, false), 0, 1, true).
+		AddItem(help, 1, 0, false)
+	layout.SetBorder(true).SetTitle("Request history")
+
+	ShowModal(pages, "history", layout, 160, 40)
+	app.SetFocus(list)
+}
+
+// historyLine is the list entry of a history entry: time, method, URL, status and duration.
+func historyLine(entry *history.Entry) string {
+	status := "[red]error[white]"
+	if entry.Response.Error == "" {
+		status = fmt.Sprintf("[%s]%d[white]", statusColor(entry.Response.StatusCode), entry.Response.StatusCode)
+	}
+	return fmt.Sprintf("%s %s %-7s %8s  %s",
+		entry.Time.Format("01-02 15:04:05"),
+		status,
+		entry.Request.Method,
+		formatDuration(time.Duration(entry.Response.Duration)),
+		tview.Escape(entry.Request.URL),
+	)
+}
+
+// historyPreview describes a history entry in full for the preview pane.
+func historyPreview(entry *history.Entry) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "[blue]Sent:[white] %s\n", entry.Time.Format("2006-01-02 15:04:05"))
+	if entry.Environment != "" {
+		fmt.Fprintf(b, "[blue]Environment:[white] %s\n", tview.Escape(entry.Environment))
+	}
+	fmt.Fprintf(b, "[yellow::b]%s %s[-::-]\n", entry.Request.Method, tview.Escape(entry.Request.URL))
+	for _, header := range entry.Request.Headers {
+		fmt.Fprintf(b, "[blue]%s:[white] %s\n", tview.Escape(header.Key), tview.Escape(header.Value))
+	}
+	request := entry.Request.Clone()
+	request.Upgrade() // Entries may predate body modes
+	if body := describeBody(request.Body); body != "" {
+		fmt.Fprintf(b, "\n%s\n", tview.Escape(body))
+	}
+	b.WriteString("\n")
+
+	if entry.Response.Error != "" {
+		fmt.Fprintf(b, "[red]%s[white]\n", tview.Escape(entry.Response.Error))
+		return b.String()
+	}
+	response := entry.Response.HttpResponseDetails()
+	fmt.Fprintf(b, "[%s::b]%d %s[-::-] in %s, %s\n", statusColor(response.StatusCode), response.StatusCode,
+		response.StatusText, formatDuration(response.Timing.Total), formatSize(response.Size))
+	if entry.Response.Truncated {
+		fmt.Fprintf(b, "[yellow]Only the first %s of the body were kept[white]\n", formatSize(history.MaxBodySize))
+	}
+	b.WriteString(tview.Escape(responseText(response)))
+	return b.String()
+}
+
+// responseText renders a response as plain text for diffing: status line, headers and the body,
+// with JSON bodies indented so changes show up line by line.
+func responseText(response httpclient.HttpResponseDetails) string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s %d %s\n", response.Protocol, response.StatusCode, response.StatusText)
+	for _, header := range response.Headers {
+		fmt.Fprintf(b, "%s: %s\n", header.Key, header.Value)
+	}
+	b.WriteString("\n")
+
+	indented := &bytes.Buffer{}
+	if err := json.Indent(indented, response.Body, "", "  "); err == nil {
+		b.Write(indented.Bytes())
+	} else {
+		b.Write(response.Body)
+	}
+	return b.String()
+}
+
+// diffText colors a line diff of two texts for a TextView.
+func diffText(before, after string) string {
+	b := &strings.Builder{}
+	for _, line := range text.DiffLines(before, after) {
+		switch line.Kind {
+		case text.DiffAdded:
+			fmt.Fprintf(b, "[green]+ %s[white]\n", tview.Escape(line.Text))
+		case text.DiffRemoved:
+			fmt.Fprintf(b, "[red]- %s[white]\n", tview.Escape(line.Text))
+		default:
+			fmt.Fprintf(b, "  %s\n", tview.Escape(line.Text))
+		}
+	}
+	return b.String()
+}
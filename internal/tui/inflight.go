@@ -0,0 +1,65 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+)
+
+// spinnerFrames are drawn one after the other while a request is in flight.
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// spinnerInterval is how often the spinner and the elapsed time are redrawn.
+const spinnerInterval = 100 * time.Millisecond
+
+// inFlight is the request that is currently being sent in the background.
+// It is only touched from the tview event loop, results are posted back with QueueUpdateDraw.
+type inFlight struct {
+	cancel context.CancelFunc // Aborts the request
+}
+
+// CancelRequest aborts the request in flight, if any. The response handler reports the cancellation.
+func CancelRequest(forms *RequestForms) {
+	if !forms.Session.Sending() {
+		return
+	}
+	LogMessage(forms.Log, "Cancelling request")
+	forms.Session.inFlight.cancel()
+}
+
+// setSendLabel switches the first button of the button panel between Send and Cancel.
+func setSendLabel(forms *RequestForms, label string) {
+	if forms.Buttons == nil {
+		return
+	}
+	if index := forms.Buttons.GetButtonIndex("Send"); index >= 0 {
+		forms.Buttons.GetButton(index).SetLabel(label)
+	} else if index := forms.Buttons.GetButtonIndex("Cancel"); index >= 0 {
+		forms.Buttons.GetButton(index).SetLabel(label)
+	}
+}
+
+// runSpinner redraws a spinner with the elapsed time below summary until ctx is done.
+func runSpinner(ctx context.Context, app *tview.Application, detailsView *tview.TextView, summary string) {
+	start := time.Now()
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		spinner := spinnerFrames[frame%len(spinnerFrames)]
+		elapsed := formatDuration(time.Since(start))
+		app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return // The response arrived while this update was queued
+			}
+			detailsView.SetText(fmt.Sprintf("%s\n\n[yellow]%c Sending... %s[white]\nCtrl+X to cancel", summary, spinner, elapsed))
+		})
+	}
+}
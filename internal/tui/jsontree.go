@@ -0,0 +1,441 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"   // External library used for creating terminal applications
+	"github.com/mattn/go-runewidth" // Width of characters on screen
+	"github.com/rivo/tview"         // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/text" // Text utilities module
+)
+
+// largeJSONNodes is the size above which a document is shown with only its top level expanded.
+const largeJSONNodes = 20000
+
+// Colors of the JSON tree.
+var (
+	jsonKeyColor     = tcell.ColorBlue
+	jsonStringColor  = tcell.ColorOrange
+	jsonIntegerColor = tcell.ColorRed
+	jsonFloatColor   = tcell.ColorGreen
+	jsonBoolColor    = tcell.ColorMediumPurple
+	jsonNullColor    = tcell.ColorDarkCyan
+	jsonPlainColor   = tcell.ColorWhite
+	jsonSummaryColor = tcell.ColorGray
+	jsonCursorColor  = tcell.ColorNavy
+	jsonMatchColor   = tcell.ColorDarkSlateGray
+	jsonCurrentColor = tcell.ColorOlive
+)
+
+// jsonRow is a line of the tree: a node, or the line closing an expanded object or array.
+type jsonRow struct {
+	node    *text.JSONNode
+	closing bool
+}
+
+// jsonSegment is a run of text drawn in one color.
+type jsonSegment struct {
+	text  string
+	color tcell.Color
+}
+
+// JSONTree shows a JSON document as a tree whose objects and arrays can be folded. Only the rows on
+// screen are drawn, so large documents stay responsive.
+type JSONTree struct {
+	*tview.Box
+
+	root    *text.JSONNode
+	rows    []jsonRow               // Visible rows, rebuilt when a node is folded or unfolded
+	cursor  int                     // Selected row
+	offset  int                     // First row on screen
+	hOffset int                     // Columns scrolled to the right
+	height  int                     // Rows on screen at the last draw, used for paging
+	matches []*text.JSONNode        // Nodes found by the last search, in document order
+	match   int                     // Current match, -1 when there is none
+	matched map[*text.JSONNode]bool // Same nodes as matches, for drawing
+}
+
+// NewJSONTree creates an empty JSON tree.
+func NewJSONTree() *JSONTree {
+	return &JSONTree{Box: tview.NewBox(), match: -1}
+}
+
+// SetData shows decoded JSON data. Large documents start with everything below the top level folded.
+func (t *JSONTree) SetData(data interface{}) {
+	t.root = text.NewJSONTree(data)
+	if t.root.Count() > largeJSONNodes {
+		t.root.SetCollapsed(true, 1)
+	}
+	t.cursor, t.offset, t.hOffset = 0, 0, 0
+	t.clearMatches()
+	t.flatten()
+}
+
+// Selected returns the node on the selected row, nil when the tree is empty.
+func (t *JSONTree) Selected() *text.JSONNode {
+	if t.cursor < 0 || t.cursor >= len(t.rows) {
+		return nil
+	}
+	return t.rows[t.cursor].node
+}
+
+// Search selects the first node at or after the selected one whose key or value contains query,
+// ignoring case, and highlights every other match. It returns the number of matches.
+func (t *JSONTree) Search(query string) int {
+	t.clearMatches()
+	if t.root == nil || query == "" {
+		return 0
+	}
+	query = strings.ToLower(query)
+	t.root.Walk(func(node *text.JSONNode) bool {
+		if strings.Contains(strings.ToLower(node.Key), query) ||
+			!node.IsContainer() && strings.Contains(strings.ToLower(node.Value), query) {
+			t.matches = append(t.matches, node)
+			t.matched[node] = true
+		}
+		return true
+	})
+	if len(t.matches) == 0 {
+		return 0
+	}
+
+	// Start from the selected node, so a new search does not jump back to the top
+	t.match = 0
+	selected := t.Selected()
+	position := map[*text.JSONNode]int{}
+	index := 0
+	t.root.Walk(func(node *text.JSONNode) bool {
+		position[node] = index
+		index++
+		return true
+	})
+	for i, node := range t.matches {
+		if selected != nil && position[node] >= position[selected] {
+			t.match = i
+			break
+		}
+	}
+	t.selectNode(t.matches[t.match])
+	return len(t.matches)
+}
+
+// NextMatch selects the next match, or the previous one when step is negative, wrapping around.
+func (t *JSONTree) NextMatch(step int) {
+	if len(t.matches) == 0 {
+		return
+	}
+	t.match = ((t.match+step)%len(t.matches) + len(t.matches)) % len(t.matches)
+	t.selectNode(t.matches[t.match])
+}
+
+// MatchStatus describes the current match, such as "match 3/17", or "" when nothing was searched.
+func (t *JSONTree) MatchStatus() string {
+	if t.match < 0 {
+		return ""
+	}
+	return fmt.Sprintf("match %d/%d", t.match+1, len(t.matches))
+}
+
+// JumpTo selects the node at a path such as $.data[3].id, unfolding its ancestors.
+func (t *JSONTree) JumpTo(path string) error {
+	if t.root == nil {
+		return fmt.Errorf("no JSON document")
+	}
+	node, err := t.root.Find(path)
+	if err != nil {
+		return err
+	}
+	t.selectNode(node)
+	return nil
+}
+
+// clearMatches forgets the results of the last search.
+func (t *JSONTree) clearMatches() {
+	t.matches, t.match, t.matched = nil, -1, map[*text.JSONNode]bool{}
+}
+
+// flatten rebuilds the visible rows from the folding state of the nodes.
+func (t *JSONTree) flatten() {
+	t.rows = t.rows[:0]
+	if t.root != nil {
+		t.addRows(t.root)
+	}
+	t.cursor = max(0, min(t.cursor, len(t.rows)-1))
+}
+
+// addRows appends the rows of a node and of its visible children.
+func (t *JSONTree) addRows(node *text.JSONNode) {
+	t.rows = append(t.rows, jsonRow{node: node})
+	if !node.IsContainer() || node.Collapsed {
+		return
+	}
+	for _, child := range node.Children {
+		t.addRows(child)
+	}
+	t.rows = append(t.rows, jsonRow{node: node, closing: true})
+}
+
+// selectNode unfolds the ancestors of a node and moves the cursor to it, in the middle of the screen.
+func (t *JSONTree) selectNode(node *text.JSONNode) {
+	node.Expand()
+	t.flatten()
+	for i, row := range t.rows {
+		if row.node == node && !row.closing {
+			t.cursor = i
+			break
+		}
+	}
+	t.offset = max(0, t.cursor-t.height/2)
+}
+
+// setCollapsed folds or unfolds the node on the selected row and keeps the cursor on it.
+func (t *JSONTree) setCollapsed(node *text.JSONNode, collapsed bool) {
+	if !node.IsContainer() || node.Collapsed == collapsed {
+		return
+	}
+	node.Collapsed = collapsed
+	t.flatten()
+	for i, row := range t.rows {
+		if row.node == node && !row.closing {
+			t.cursor = i
+			break
+		}
+	}
+}
+
+// Draw draws the visible rows, highlighting the cursor and the search matches.
+func (t *JSONTree) Draw(screen tcell.Screen) {
+	t.Box.DrawForSubclass(screen, t)
+	x, y, width, height := t.GetInnerRect()
+	t.height = height
+
+	// Keep the cursor on screen
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+	t.offset = max(0, min(t.offset, len(t.rows)-height))
+
+	for line := 0; line < height && t.offset+line < len(t.rows); line++ {
+		index := t.offset + line
+		row := t.rows[index]
+
+		background := tcell.ColorDefault
+		switch {
+		case index == t.cursor && t.HasFocus():
+			background = jsonCursorColor
+		case !row.closing && t.match >= 0 && t.matches[t.match] == row.node:
+			background = jsonCurrentColor
+		case !row.closing && t.matched[row.node]:
+			background = jsonMatchColor
+		}
+		drawSegments(screen, x, y+line, width-1, t.hOffset, jsonRowSegments(row), background)
+	}
+	drawScrollbar(screen, x, y, width, height, len(t.rows), t.offset)
+}
+
+// jsonRowSegments returns the colored text of a row.
+func jsonRowSegments(row jsonRow) []jsonSegment {
+	node := row.node
+	indent := strings.Repeat("  ", node.Depth)
+	comma := ""
+	if node.Parent != nil && node != node.Parent.Children[len(node.Parent.Children)-1] {
+		comma = ","
+	}
+	opener, closer := "{", "}"
+	if node.Kind == text.JSONArray {
+		opener, closer = "[", "]"
+	}
+	if row.closing {
+		return []jsonSegment{{indent + "  " + closer + comma, jsonPlainColor}}
+	}
+
+	marker := "  "
+	if node.IsContainer() {
+		marker = "▾ "
+		if node.Collapsed {
+			marker = "▸ "
+		}
+	}
+	segments := []jsonSegment{{indent + marker, jsonPlainColor}}
+	if node.Parent != nil && node.Parent.Kind == text.JSONObject {
+		segments = append(segments,
+			jsonSegment{text.QuoteJSONString(node.Key), jsonKeyColor},
+			jsonSegment{": ", jsonPlainColor},
+		)
+	}
+
+	switch node.Kind {
+	case text.JSONObject, text.JSONArray:
+		if !node.Collapsed {
+			return append(segments, jsonSegment{opener, jsonPlainColor})
+		}
+		unit := "key"
+		if node.Kind == text.JSONArray {
+			unit = "item"
+		}
+		if len(node.Children) != 1 {
+			unit += "s"
+		}
+		return append(segments,
+			jsonSegment{opener + "…" + closer + comma, jsonPlainColor},
+			jsonSegment{fmt.Sprintf(" %d %s", len(node.Children), unit), jsonSummaryColor},
+		)
+	case text.JSONString:
+		segments = append(segments, jsonSegment{text.QuoteJSONString(node.Value), jsonStringColor})
+	case text.JSONNumber:
+		color := jsonIntegerColor
+		if strings.ContainsAny(node.Value, ".eE") {
+			color = jsonFloatColor
+		}
+		segments = append(segments, jsonSegment{node.Value, color})
+	case text.JSONBool:
+		segments = append(segments, jsonSegment{node.Value, jsonBoolColor})
+	case text.JSONNull:
+		segments = append(segments, jsonSegment{node.Value, jsonNullColor})
+	default:
+		segments = append(segments, jsonSegment{node.Value, jsonPlainColor})
+	}
+	return append(segments, jsonSegment{comma, jsonPlainColor})
+}
+
+// drawSegments draws a line of segments, skipping the first skip columns, and fills the rest of the
+// line with the background color.
+func drawSegments(screen tcell.Screen, x, y, width, skip int, segments []jsonSegment, background tcell.Color) {
+	column := 0
+	for _, segment := range segments {
+		style := tcell.StyleDefault.Foreground(segment.color).Background(background)
+		for _, r := range segment.text {
+			w := runewidth.RuneWidth(r)
+			if column >= skip && column-skip+w <= width {
+				screen.SetContent(x+column-skip, y, r, nil, style)
+			}
+			column += w
+		}
+	}
+	for column = max(column-skip, 0); column < width; column++ {
+		screen.SetContent(x+column, y, ' ', nil, tcell.StyleDefault.Background(background))
+	}
+}
+
+// InputHandler moves the cursor and folds nodes:
+// Up/Down/PgUp/PgDn/Home/End (or k/j/g/G) move, Enter or Space folds or unfolds,
+// Left/h folds or goes to the parent, Right unfolds, E unfolds and C folds everything,
+// n/N go to the next/previous search match and </> scroll sideways.
+func (t *JSONTree) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if len(t.rows) == 0 {
+			return
+		}
+		row := t.rows[t.cursor]
+		page := max(1, t.height-1)
+
+		switch event.Key() {
+		case tcell.KeyUp:
+			t.cursor--
+		case tcell.KeyDown:
+			t.cursor++
+		case tcell.KeyPgUp:
+			t.cursor -= page
+		case tcell.KeyPgDn:
+			t.cursor += page
+		case tcell.KeyHome:
+			t.cursor = 0
+		case tcell.KeyEnd:
+			t.cursor = len(t.rows) - 1
+		case tcell.KeyEnter:
+			t.setCollapsed(row.node, !row.node.Collapsed)
+		case tcell.KeyLeft:
+			t.foldOrParent(row)
+		case tcell.KeyRight:
+			t.setCollapsed(row.node, false)
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'k':
+				t.cursor--
+			case 'j':
+				t.cursor++
+			case 'g':
+				t.cursor = 0
+			case 'G':
+				t.cursor = len(t.rows) - 1
+			case ' ':
+				t.setCollapsed(row.node, !row.node.Collapsed)
+			case 'h':
+				t.foldOrParent(row)
+			case 'E', 'C':
+				selected := row.node
+				t.root.SetCollapsed(event.Rune() == 'C', 1)
+				if event.Rune() == 'C' {
+					for selected.Depth > 1 {
+						selected = selected.Parent
+					}
+				}
+				t.selectNode(selected)
+			case 'n':
+				t.NextMatch(1)
+			case 'N':
+				t.NextMatch(-1)
+			case '<':
+				t.hOffset = max(0, t.hOffset-8)
+			case '>':
+				t.hOffset += 8
+			}
+		}
+		t.cursor = max(0, min(t.cursor, len(t.rows)-1))
+	})
+}
+
+// foldOrParent folds an unfolded object or array, or moves the cursor to the parent of anything else.
+func (t *JSONTree) foldOrParent(row jsonRow) {
+	if row.node.IsContainer() && !row.node.Collapsed {
+		t.setCollapsed(row.node, true)
+		return
+	}
+	if row.node.Parent != nil {
+		t.selectNode(row.node.Parent)
+	}
+}
+
+// MouseHandler selects the clicked row, folds or unfolds it on a click on its marker or a double
+// click, and scrolls with the wheel.
+func (t *JSONTree) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+	return t.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (consumed bool, capture tview.Primitive) {
+		x, y := event.Position()
+		if !t.InRect(x, y) {
+			return false, nil
+		}
+		rectX, rectY, _, _ := t.GetInnerRect()
+
+		switch action {
+		case tview.MouseLeftClick, tview.MouseLeftDoubleClick:
+			setFocus(t)
+			index := t.offset + y - rectY
+			if index < 0 || index >= len(t.rows) {
+				return true, nil
+			}
+			t.cursor = index
+			row := t.rows[index]
+			markerColumn := rectX + row.node.Depth*2 - t.hOffset
+			onMarker := x >= markerColumn && x < markerColumn+2
+			if onMarker == (action == tview.MouseLeftClick) {
+				t.setCollapsed(row.node, !row.node.Collapsed)
+			}
+			return true, nil
+		case tview.MouseScrollUp, tview.MouseScrollDown:
+			step := 3
+			if action == tview.MouseScrollUp {
+				step = -3
+			}
+			t.offset = max(0, min(t.offset+step, len(t.rows)-t.height))
+			t.cursor = max(t.offset, min(t.cursor, t.offset+t.height-1))
+			return true, nil
+		}
+		return false, nil
+	})
+}
@@ -0,0 +1,56 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+)
+
+// ShowModal centers a primitive of the given size on top of the root pages and focuses it.
+func ShowModal(pages *tview.Pages, name string, primitive tview.Primitive, width, height int) {
+	centered := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(primitive, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+	pages.AddPage(name, centered, true, true)
+}
+
+// HideModal removes a dialog previously shown with ShowModal.
+func HideModal(pages *tview.Pages, name string) {
+	pages.RemovePage(name)
+}
+
+// Prompt asks for a single line of text. done is only called when the user confirms.
+func Prompt(pages *tview.Pages, title, label, initial string, done func(text string)) {
+	form := tview.NewForm().AddInputField(label, initial, 40, nil, nil)
+	form.AddButton("OK", func() {
+		text := form.GetFormItem(0).(*tview.InputField).GetText()
+		HideModal(pages, "prompt")
+		done(text)
+	})
+	form.AddButton("Cancel", func() {
+		HideModal(pages, "prompt")
+	})
+	form.SetCancelFunc(func() {
+		HideModal(pages, "prompt")
+	})
+	form.SetBorder(true).SetTitle(title)
+
+	ShowModal(pages, "prompt", form, 60, 7)
+}
+
+// Confirm asks a yes/no question. yes is only called when the user confirms.
+func Confirm(pages *tview.Pages, text string, yes func()) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(_ int, label string) {
+			pages.RemovePage("confirm")
+			if label == "Yes" {
+				yes()
+			}
+		})
+	pages.AddPage("confirm", modal, false, true)
+}
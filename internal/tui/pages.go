@@ -9,7 +9,7 @@ import (
 // InitHTMLPages initializes a Pages structure with several Form pages and an automatic keyboard input
 // handler for switching between these pages via the Tab key.
 func InitHTMLPages(
-	paramsForm, headersForm, bodyForm, tokenForm *tview.Form, // Input forms for different request components
+	paramsForm, headersForm, bodyForm, tokenForm, testsForm *tview.Form, // Input forms for different request components
 	logView *tview.TextView, // TextView used for logging activities
 ) *tview.Pages {
 	// Create a new Pages object and add several Form pages to it
@@ -17,10 +17,11 @@ func InitHTMLPages(
 		AddPage("Params", paramsForm, true, true).
 		AddPage("Headers", headersForm, true, false).
 		AddPage("Body", bodyForm, true, false).
-		AddPage("Token", tokenForm, true, false)
+		AddPage("Token", tokenForm, true, false).
+		AddPage("Tests", testsForm, true, false)
 
 	// List of page names in the same order they were added to htmlPages
-	requestsPageNames := []string{"Params", "Headers", "Body", "Token"}
+	requestsPageNames := []string{"Params", "Headers", "Body", "Token", "Tests"}
 	currentPageIndex := 0 // Index of the currently shown page
 
 	// Set an input capture function that switches to the next page when the Tab key is pressed
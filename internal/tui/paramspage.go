@@ -5,27 +5,154 @@ import (
 	"fmt" // Standard library used for formatted I/O operations
 
 	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/collection" // Saved requests module
 )
 
-// AddKeyValueFieldsToForm takes two form parameter and field index.
-// It adds key-value fields to the form and updates URL with the parameters when they are modified.
-func AddKeyValueFieldsToForm(paramsForm, urlForm *tview.Form, index int, logView *tview.TextView) {
-	// Formatting the label of the Key input field
-	keyLabel := fmt.Sprintf("┌Key %d:", index)
-	valueLabel := "└Value:"
-
-	// Adding a new Key input field and a Value input field to the Params form
-	paramsForm.
-		// Add key field
-		AddInputField(keyLabel, "", 50, func(textToCheck string, lastChar rune) bool {
-			// Updating URL whenever there is a change in params
-			UpdateURLWithParams(urlForm, paramsForm, logView)
-			return true
-		}, nil).
-		// Add value field
-		AddInputField(valueLabel, "", 50, func(textToCheck string, lastChar rune) bool {
-			// Updating URL whenever there is a change in params
-			UpdateURLWithParams(urlForm, paramsForm, logView)
-			return true
-		}, nil)
+// ParamsEditor is the Params page. It keeps its rows and the query of the URL in sync: editing a row
+// rewrites the query, editing the URL rebuilds the rows. Rows keep their order, repeated keys and raw
+// encoding, and a row can be switched off, which takes it out of the URL but keeps it on the page.
+// The :name and {name} segments of the path get a value field each, above the query rows.
+type ParamsEditor struct {
+	*tview.Form
+
+	url        *tview.InputField     // URL input whose query the rows mirror
+	rows       []collection.KeyValue // Every row, enabled or not, in display order
+	path       []collection.KeyValue // Path params of the URL, in the order they appear
+	pathValues map[string]string     // Every path param value typed so far, kept while a name is being retyped
+	syncing    bool                  // Set while one side is written from the other, so it is not synced back
+	changed    func()                // Called after the URL, a row or a path param value was edited
+}
+
+// NewParamsEditor creates the Params page. It is connected to the URL input by InitParamsForm.
+func NewParamsEditor() *ParamsEditor {
+	return &ParamsEditor{Form: tview.NewForm(), pathValues: make(map[string]string)}
+}
+
+// Params returns every row with a key or a value, disabled ones included.
+func (p *ParamsEditor) Params() []collection.KeyValue {
+	var params []collection.KeyValue
+	for _, row := range p.rows {
+		if !row.IsEmpty() {
+			params = append(params, row)
+		}
+	}
+	return params
+}
+
+// PathParams returns the path params of the URL with their values.
+func (p *ParamsEditor) PathParams() []collection.KeyValue {
+	return append([]collection.KeyValue(nil), p.path...)
+}
+
+// Load shows a URL and its params. The saved params are used when their enabled rows still match the
+// query of the URL, which brings back disabled rows; otherwise the rows are read from the URL.
+func (p *ParamsEditor) Load(rawURL string, saved, path []collection.KeyValue) {
+	p.pathValues = make(map[string]string)
+	for _, param := range path {
+		p.pathValues[param.Key] = param.Value
+	}
+	p.updatePath(rawURL)
+
+	p.rows = collection.RestoreParams(rawURL, saved)
+
+	p.syncing = true
+	p.url.SetText(rawURL)
+	p.syncing = false
+	p.buildRows()
+}
+
+// SetChangedFunc sets a handler called whenever the URL, a row or a path param value is edited.
+func (p *ParamsEditor) SetChangedFunc(handler func()) {
+	p.changed = handler
+}
+
+// UpdateURLWithParams rewrites the query of the URL from the enabled rows, keeping the rest of the URL.
+func (p *ParamsEditor) UpdateURLWithParams() {
+	if p.syncing {
+		return
+	}
+	current := p.url.GetText()
+	base, _, fragment := collection.SplitURL(current)
+	if updated := collection.JoinURL(base, p.rows, fragment); updated != current {
+		p.syncing = true
+		p.url.SetText(updated)
+		p.syncing = false
+	}
+	p.notify()
+}
+
+// UpdateParamsFromURL rebuilds the rows from the query of the URL. Disabled rows are not in the URL,
+// they stay where they were between the rows read from it.
+func (p *ParamsEditor) UpdateParamsFromURL() {
+	if p.syncing {
+		return
+	}
+	p.updatePath(p.url.GetText())
+	_, params, _ := collection.SplitURL(p.url.GetText())
+	p.rows = collection.MergeParams(p.rows, params)
+	p.buildRows()
+	p.notify()
+}
+
+// notify calls the changed handler, if there is one.
+func (p *ParamsEditor) notify() {
+	if p.changed != nil {
+		p.changed()
+	}
+}
+
+// updatePath lists the path params of a URL, with the values typed for them before.
+func (p *ParamsEditor) updatePath(rawURL string) {
+	p.path = nil
+	for _, name := range collection.PathParamNames(rawURL) {
+		p.path = append(p.path, collection.KeyValue{Key: name, Value: p.pathValues[name]})
+	}
+}
+
+// buildRows replaces the items of the page with a field per path param and one row per query param,
+// and an empty row when there are none.
+func (p *ParamsEditor) buildRows() {
+	p.syncing = true
+	defer func() { p.syncing = false }()
+
+	p.Clear(false)
+	for i := range p.path {
+		p.addPathParam(i)
+	}
+	if len(p.rows) == 0 {
+		p.rows = append(p.rows, collection.KeyValue{})
+	}
+	for i := range p.rows {
+		p.addRow(i)
+	}
+}
+
+// addRow adds the items of row i: a checkbox sending the param or not, its key and its value.
+func (p *ParamsEditor) addRow(i int) {
+	row := p.rows[i]
+	p.AddCheckbox("┌Send", !row.Disabled, func(checked bool) {
+		p.rows[i].Disabled = !checked
+		p.UpdateURLWithParams()
+	})
+	p.AddInputField(fmt.Sprintf("│Key %d", i+1), row.Key, 50, nil, func(text string) {
+		p.rows[i].Key = text
+		p.UpdateURLWithParams()
+	})
+	p.AddInputField("└Value", row.Value, 50, nil, func(text string) {
+		p.rows[i].Value = text
+		p.rows[i].NoValue = p.rows[i].NoValue && text == ""
+		p.UpdateURLWithParams()
+	})
+}
+
+// addPathParam adds the value field of path param i. Path params live in the URL, so typing a value
+// leaves the URL as it is.
+func (p *ParamsEditor) addPathParam(i int) {
+	param := p.path[i]
+	p.AddInputField("Path :"+param.Key, param.Value, 50, nil, func(text string) {
+		p.path[i].Value = text
+		p.pathValues[param.Key] = text
+		p.notify()
+	})
 }
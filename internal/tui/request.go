@@ -0,0 +1,170 @@
+// Package tui holds components for terminal-based user interfaces
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview" // Terminal UI library
+
+	"github.com/SiirRandall/go-restful/internal/collection"            // Saved requests module
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+)
+
+// RequestForms bundles the forms that together describe the request being edited. Once bound to a
+// Session every edit made in them is written into the request of the session.
+type RequestForms struct {
+	URL     *tview.Form     // Form holding the URL input field
+	Method  *MethodBox      // Method dropdown
+	Params  *ParamsEditor   // Params page
+	Headers *HeadersEditor  // Headers page
+	Body    *BodyEditor     // Body page
+	Token   *AuthEditor     // Token page
+	Tests   *TestsEditor    // Tests page
+	Log     *tview.TextView // Log view used to report problems
+	Viewer  *ResponseViewer // Response viewer, its filter is saved with the request
+	Details *tview.TextView // Details view showing the request sent and the status, checks and headers of the response
+	Buttons *tview.Form     // Send and Quit buttons, Send turns into Cancel while a request is in flight
+
+	Session *Session // Model the forms write into, nil until Bind is called
+
+	loading bool // Set while Load fills the forms, their edits are read once it is done
+}
+
+// Bind connects the forms to a session: from now on every edit is written into its request. The
+// request is read from the forms once, so the session starts with what they show.
+func (f *RequestForms) Bind(session *Session) {
+	f.Session = session
+	f.Params.SetChangedFunc(f.update)
+	f.Headers.SetChangedFunc(f.update)
+	f.Body.SetChangedFunc(f.update)
+	f.Token.SetChangedFunc(f.update)
+	f.Tests.SetChangedFunc(f.update)
+	if f.Viewer != nil {
+		f.Viewer.SetFilterFunc(f.update)
+	}
+	f.update()
+}
+
+// Request returns a copy of the request being edited.
+func (f *RequestForms) Request() *collection.Request {
+	return f.Session.Request.Clone()
+}
+
+// update reads every form into the request of the session. Fields that have no form of their own,
+// such as the name or the settings overrides, are kept as they are.
+func (f *RequestForms) update() {
+	if f.Session == nil || f.loading {
+		return
+	}
+	request := f.Session.Request.Clone()
+	request.URL = f.Params.url.GetText()
+	request.Params = f.Params.Params()
+	request.Path = f.Params.PathParams()
+	request.Headers = f.Headers.Headers()
+	if f.Method != nil {
+		request.Method = f.Method.Method()
+	}
+
+	request.Body = f.Body.Body()
+	request.Auth = f.Token.Auth()
+	request.Assertions = f.Tests.Assertions()
+	request.Extract = f.Tests.Extractions()
+
+	if f.Viewer != nil {
+		request.Filter = f.Viewer.Filter()
+	}
+
+	f.Session.Request = request
+}
+
+// Load fills every form from a saved request, which becomes the request of the session.
+func (f *RequestForms) Load(request *collection.Request) {
+	request = request.Clone()
+	request.Upgrade() // Requests from the history may predate the current format
+	f.Session.Request = request
+
+	f.loading = true
+	// Select the method, adding it to the dropdown if it is not one of the known ones
+	f.Method.Select(request.Method)
+
+	// The URL is the source of truth for query params, the saved ones only add the disabled rows
+	f.Params.Load(request.URL, request.Params, request.Path)
+
+	f.Headers.Load(request.Headers)
+
+	f.Body.Load(request.Body)
+
+	f.Token.Load(request.Auth)
+
+	f.Tests.Load(request.Assertions, request.Extract)
+
+	if f.Viewer != nil {
+		f.Viewer.SetFilter(request.Filter)
+	}
+	f.loading = false
+	f.update() // The forms normalize what they show, such as the rows of the params
+}
+
+// formInputFields returns the input fields of a form in order, skipping any other kind of item.
+func formInputFields(form *tview.Form) []*tview.InputField {
+	var fields []*tview.InputField
+	for i := 0; i < form.GetFormItemCount(); i++ {
+		if field, ok := form.GetFormItem(i).(*tview.InputField); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// FormKeyValues walks a form and returns its input fields as ordered key/value pairs.
+// Items that are not input fields (text areas, dropdowns...) are skipped, and rows with an empty key are ignored.
+func FormKeyValues(form *tview.Form) []collection.KeyValue {
+	fields := formInputFields(form)
+
+	var pairs []collection.KeyValue
+	for i := 0; i+1 < len(fields); i += 2 { // Fields always come in Key, Value order
+		key := strings.TrimSpace(fields[i].GetText())
+		if key == "" {
+			continue
+		}
+		pairs = append(pairs, collection.KeyValue{Key: key, Value: fields[i+1].GetText()})
+	}
+	return pairs
+}
+
+// SetFormKeyValues writes pairs into the key/value rows of a form, calling addRow until there are enough rows.
+// Rows left over from a previous request are cleared.
+func SetFormKeyValues(form *tview.Form, pairs []collection.KeyValue, addRow func()) {
+	for len(formInputFields(form))/2 < len(pairs) {
+		before := form.GetFormItemCount()
+		addRow()
+		if form.GetFormItemCount() == before { // The form cannot grow, write what fits
+			break
+		}
+	}
+
+	fields := formInputFields(form)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, value := "", ""
+		if i/2 < len(pairs) {
+			key, value = pairs[i/2].Key, pairs[i/2].Value
+		}
+		fields[i].SetText(key)
+		fields[i+1].SetText(value)
+	}
+}
+
+// AddKeyValueFields appends another plain key/value row to a form, such as the environment variables.
+func AddKeyValueFields(form *tview.Form) {
+	index := len(formInputFields(form))/2 + 1
+	form.AddInputField(fmt.Sprintf("┌Key %d:", index), "", 50, nil, nil)
+	form.AddInputField("└Value", "", 50, nil, nil)
+}
+
+// BuildRequestDetails assembles a complete HttpRequestDetails from the request of the session,
+// with the variables of the active environment filled in and the global client settings merged
+// with the request's overrides.
+func BuildRequestDetails(session *Session) (httpclient.HttpRequestDetails, error) {
+	return session.Resolved().HttpRequestDetails(session.Settings.Client)
+}
@@ -0,0 +1,346 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2" // External library used for creating terminal applications
+	"github.com/rivo/tview"       // External library used for managing terminal-based user interfaces
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // Responses being shown
+	"github.com/SiirRandall/go-restful/internal/jsonpath"              // JSONPath and jq filters
+	"github.com/SiirRandall/go-restful/internal/render"                // Renderers by Content-Type
+)
+
+// Pages of the response viewer.
+const (
+	responseTextPage  = "text"  // Text bodies, pretty printed or raw
+	responseTreePage  = "tree"  // JSON bodies
+	responseTablePage = "table" // CSV bodies
+)
+
+// ResponseViewer shows the body of a response with the renderer of its Content-Type: JSON as a
+// foldable tree, CSV as a table, XML, HTML and YAML pretty printed, images as their metadata and
+// binary bodies as a hex dump. 'r' switches between that and the body as it was received. In the
+// tree, '/' searches keys and values and ':' jumps to a path such as $.data[3].id; the prompt for
+// either opens under the tree and closes with Enter or Escape. 'f' goes to the filter input at the
+// bottom, whose JSONPath or jq expression narrows the tree down to what it selects.
+type ResponseViewer struct {
+	*tview.Flex
+
+	Text  *ScrollTextView // Viewer of text bodies
+	Tree  *JSONTree       // Viewer of JSON bodies
+	Table *tview.Table    // Viewer of CSV bodies
+
+	pages      *tview.Pages      // Shows Text or Tree
+	prompt     *tview.InputField // Search or path input, only shown while typing
+	promptKind rune              // '/' while searching, ':' while jumping, 0 when the prompt is closed
+	filter     *tview.InputField // JSONPath or jq filter of JSON bodies
+	data       interface{}       // Whole JSON body, before filtering
+	showTree   bool              // Set while a JSON body is shown
+	message    string            // Result of the last filter, search or jump, shown in the title
+	filtered   func()            // Called after a filter was applied with Enter
+
+	response httpclient.HttpResponseDetails // Response whose body is shown
+	renderer string                         // Name of the renderer of the body, "" until a body is shown
+	raw      bool                           // Set to show bodies as they were received
+}
+
+// tableRows is the content of the table viewer: the first row holds the column names and stays on
+// top, rows may have fewer cells than others.
+type tableRows struct {
+	tview.TableContentReadOnly
+	rows    [][]string
+	columns int
+}
+
+// GetCell returns the cell at a position, an empty one past the end of a short row.
+func (t *tableRows) GetCell(row, column int) *tview.TableCell {
+	text := ""
+	if row < len(t.rows) && column < len(t.rows[row]) {
+		text = t.rows[row][column]
+	}
+	cell := tview.NewTableCell(tview.Escape(text)).SetMaxWidth(40)
+	if row == 0 {
+		cell.SetTextColor(tcell.ColorYellow).SetAttributes(tcell.AttrBold).SetSelectable(false)
+	}
+	return cell
+}
+
+// GetRowCount returns the number of rows, the column names included.
+func (t *tableRows) GetRowCount() int {
+	return len(t.rows)
+}
+
+// GetColumnCount returns the number of cells of the longest row.
+func (t *tableRows) GetColumnCount() int {
+	return t.columns
+}
+
+// ShowResponse shows the body of a response with the renderer its Content-Type, or its content, calls
+// for; while raw is toggled on the body is shown as it was received. A body the renderer cannot read
+// is shown raw, with the reason in the title.
+func (v *ResponseViewer) ShowResponse(response httpclient.HttpResponseDetails) {
+	v.response = response
+	v.render()
+}
+
+// render shows the response body, pretty or raw.
+func (v *ResponseViewer) render() {
+	renderer := render.Lookup(v.response)
+	v.renderer = renderer.Name
+	if v.raw {
+		v.show(render.Raw(v.response))
+		return
+	}
+	output, err := renderer.Render(v.response)
+	if err != nil {
+		v.show(render.Raw(v.response))
+		v.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+		return
+	}
+	v.show(output)
+}
+
+// show switches to the page of a rendered body.
+func (v *ResponseViewer) show(output render.Output) {
+	switch {
+	case output.JSON != nil:
+		v.ShowJSON(output.JSON)
+	case output.Table != nil:
+		v.showTable(output.Table)
+	default:
+		v.showText(output.Text)
+	}
+}
+
+// ShowJSON shows decoded JSON data in the tree, through the filter when there is one.
+func (v *ResponseViewer) ShowJSON(data interface{}) {
+	v.data = data
+	v.showTree = true
+	v.closePrompt(nil)
+	v.applyFilter()
+	v.pages.SwitchToPage(responseTreePage)
+}
+
+// showText shows a body as text with color tags.
+func (v *ResponseViewer) showText(body string) {
+	v.Text.SetText(body)
+	v.Text.SetMaxLines(0)
+	v.Text.ScrollToBeginning()
+	v.data, v.showTree, v.message = nil, false, ""
+	v.closePrompt(nil)
+	v.pages.SwitchToPage(responseTextPage)
+}
+
+// showTable shows rows in the table, the first one as the column names.
+func (v *ResponseViewer) showTable(rows [][]string) {
+	content := &tableRows{rows: rows}
+	for _, row := range rows {
+		content.columns = max(content.columns, len(row))
+	}
+	v.Table.SetContent(content)
+	v.Table.Select(1, 0).ScrollToBeginning()
+	v.data, v.showTree = nil, false
+	v.message = fmt.Sprintf("%d rows", len(rows)-1)
+	if len(rows) == 2 {
+		v.message = "1 row"
+	}
+	v.closePrompt(nil)
+	v.pages.SwitchToPage(responseTablePage)
+}
+
+// toggleRaw switches between the rendered body and the body as it was received.
+func (v *ResponseViewer) toggleRaw(setFocus func(p tview.Primitive)) {
+	v.raw = !v.raw
+	if v.renderer != "" {
+		v.render()
+		v.focusBody(setFocus)
+	}
+}
+
+// Filter returns the filter expression.
+func (v *ResponseViewer) Filter() string {
+	return v.filter.GetText()
+}
+
+// SetFilter replaces the filter expression and applies it to the JSON body shown.
+func (v *ResponseViewer) SetFilter(expr string) {
+	v.filter.SetText(expr)
+	if v.showTree {
+		v.applyFilter()
+	}
+}
+
+// SetFilterFunc sets a handler called whenever a filter is applied with Enter.
+func (v *ResponseViewer) SetFilterFunc(handler func()) {
+	v.filtered = handler
+}
+
+// Prompting reports whether keys go to the search, path or filter input.
+func (v *ResponseViewer) Prompting() bool {
+	return v.promptKind != 0 || v.filter.HasFocus()
+}
+
+// applyFilter shows the part of the JSON body the filter selects, or the whole body when there is
+// no filter or it is not valid.
+func (v *ResponseViewer) applyFilter() {
+	v.message = ""
+	if !v.showTree {
+		if v.filter.GetText() != "" && v.raw && v.renderer == "JSON" {
+			v.message = "[red]filters only apply to pretty JSON, 'r' switches to it[-]"
+		} else if v.filter.GetText() != "" {
+			v.message = "[red]filters only apply to JSON[-]"
+		}
+		return
+	}
+	expr := v.filter.GetText()
+	if expr == "" {
+		v.Tree.SetData(v.data)
+		return
+	}
+	filtered, err := jsonpath.Filter(v.data, expr)
+	if err != nil {
+		v.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+		v.Tree.SetData(v.data)
+		return
+	}
+	if results, ok := filtered.([]interface{}); ok {
+		v.message = fmt.Sprintf("filtered, %d results", len(results))
+	} else {
+		v.message = "filtered"
+	}
+	v.Tree.SetData(filtered)
+}
+
+// openPrompt opens the search prompt ('/') or the path prompt (':') under the tree.
+func (v *ResponseViewer) openPrompt(kind rune, setFocus func(p tview.Primitive)) {
+	v.promptKind = kind
+	if kind == '/' {
+		v.prompt.SetLabel("Search: ").SetText("")
+	} else {
+		path := ""
+		if selected := v.Tree.Selected(); selected != nil {
+			path = selected.Path()
+		}
+		v.prompt.SetLabel("Path: ").SetText(path)
+	}
+	v.ResizeItem(v.prompt, 1, 0)
+	setFocus(v.prompt)
+}
+
+// focusBody gives the focus to the tree, the table or the text, whichever is shown.
+func (v *ResponseViewer) focusBody(setFocus func(p tview.Primitive)) {
+	setFocus(v.body())
+}
+
+// body returns the tree, the table or the text, whichever is shown.
+func (v *ResponseViewer) body() tview.Primitive {
+	if v.showTree {
+		return v.Tree
+	}
+	if name, _ := v.pages.GetFrontPage(); name == responseTablePage {
+		return v.Table
+	}
+	return v.Text
+}
+
+// closePrompt hides the prompt and gives the focus back to the tree.
+func (v *ResponseViewer) closePrompt(setFocus func(p tview.Primitive)) {
+	v.promptKind = 0
+	v.ResizeItem(v.prompt, 0, 0)
+	if setFocus != nil {
+		setFocus(v.Tree)
+	}
+}
+
+// runPrompt searches or jumps with the text of the prompt.
+func (v *ResponseViewer) runPrompt() {
+	query := v.prompt.GetText()
+	if v.promptKind == '/' {
+		if v.Tree.Search(query) == 0 && query != "" {
+			v.message = fmt.Sprintf("[red]no match for %s[-]", tview.Escape(query))
+		} else {
+			v.message = ""
+		}
+		return
+	}
+	if err := v.Tree.JumpTo(query); err != nil {
+		v.message = "[red]" + tview.Escape(err.Error()) + "[-]"
+	} else {
+		v.message = ""
+	}
+}
+
+// InputHandler sends keys to the filter or the prompt while they have the focus, moves to the filter
+// on 'f', switches between pretty and raw on 'r', opens the prompt on '/' or ':' in the tree, and
+// hands every other key to the text, the table or the tree.
+func (v *ResponseViewer) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return v.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if v.filter.HasFocus() {
+			switch event.Key() {
+			case tcell.KeyEnter:
+				v.applyFilter()
+				v.focusBody(setFocus)
+				if v.filtered != nil {
+					v.filtered()
+				}
+			case tcell.KeyEscape:
+				v.focusBody(setFocus)
+			default:
+				v.filter.InputHandler()(event, setFocus)
+			}
+			return
+		}
+		if v.Prompting() {
+			switch event.Key() {
+			case tcell.KeyEnter:
+				v.runPrompt()
+				v.closePrompt(setFocus)
+			case tcell.KeyEscape:
+				v.closePrompt(setFocus)
+			default:
+				v.prompt.InputHandler()(event, setFocus)
+			}
+			return
+		}
+		if event.Rune() == 'f' {
+			setFocus(v.filter)
+			return
+		}
+		if event.Rune() == 'r' {
+			v.toggleRaw(setFocus)
+			return
+		}
+		if v.showTree && (event.Rune() == '/' || event.Rune() == ':') {
+			v.openPrompt(event.Rune(), setFocus)
+			return
+		}
+		v.body().InputHandler()(event, setFocus)
+	})
+}
+
+// Draw draws the viewer, with the renderer, the selected path and the search results of the tree in
+// its title.
+func (v *ResponseViewer) Draw(screen tcell.Screen) {
+	title := "JSON Viewer"
+	if v.renderer != "" {
+		title = v.renderer + " Viewer"
+	}
+	if v.raw {
+		title += " - raw"
+	}
+	if v.showTree {
+		if selected := v.Tree.Selected(); selected != nil {
+			title += " - " + tview.Escape(selected.Path())
+		}
+		if status := v.Tree.MatchStatus(); status != "" {
+			title += " - " + status
+		}
+	}
+	if v.message != "" {
+		title += " - " + v.message
+	}
+	v.SetTitle(title)
+	v.Flex.Draw(screen)
+}
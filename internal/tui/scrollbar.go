@@ -30,10 +30,15 @@ func (stv *ScrollTextView) Draw(screen tcell.Screen) {
 
 	// Count total number of lines in text view
 	totalRows := strings.Count(stv.GetText(true), "\n")
+	scrollPosition, _ := stv.GetScrollOffset()
+	drawScrollbar(screen, x, y, width, height, totalRows, scrollPosition)
+}
 
+// drawScrollbar draws a scrollbar along the right edge of an area showing height of totalRows rows,
+// scrolled down by scrollPosition rows. Nothing is drawn when every row fits.
+func drawScrollbar(screen tcell.Screen, x, y, width, height, totalRows, scrollPosition int) {
 	if totalRows > height {
 		// Calculate scrolling position and percentage scrolled
-		scrollPosition, _ := stv.GetScrollOffset()
 		percentageScrolled := float64(scrollPosition) / float64(totalRows-height+1)
 
 		// Calculate scrollbar properties including its height and Y position on screen
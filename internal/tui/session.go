@@ -0,0 +1,49 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"github.com/SiirRandall/go-restful/internal/collection"            // Saved requests module
+	"github.com/SiirRandall/go-restful/internal/environment"           // Environments and variables module
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+	"github.com/SiirRandall/go-restful/internal/settings"              // Application wide defaults
+)
+
+// Session is the state of the application behind its widgets: the request being edited, the
+// environment and settings it is sent with, and the request in flight with the last response. The
+// pages of the editor write every edit into Request as it is typed, and sending, saving, exporting
+// and the dialogs read the request from here rather than from the form items. It holds no widget,
+// so a session can be created and changed without a screen.
+type Session struct {
+	Request     *collection.Request      // Request being edited, kept up to date by the forms
+	Environment *environment.Environment // Active environment, nil when none is selected
+	Settings    settings.Settings        // Application wide defaults, a request may override the client settings
+
+	inFlight *inFlight                       // Request being sent in the background, nil when idle
+	response *httpclient.HttpResponseDetails // Response shown in the viewers, nil when there is none
+}
+
+// NewSession returns a session editing an empty GET request, sent with the given settings.
+func NewSession(defaults settings.Settings) *Session {
+	return &Session{
+		Request:  &collection.Request{Method: "GET"},
+		Settings: defaults,
+	}
+}
+
+// Resolved returns a copy of the request with the variables of the active environment filled in.
+func (s *Session) Resolved() *collection.Request {
+	return s.Environment.Apply(s.Request)
+}
+
+// EnvironmentName returns the name of the active environment, "" when none is selected.
+func (s *Session) EnvironmentName() string {
+	if s.Environment == nil {
+		return ""
+	}
+	return s.Environment.Name
+}
+
+// Sending reports whether a request is in flight.
+func (s *Session) Sending() bool {
+	return s.inFlight != nil
+}
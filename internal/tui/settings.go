@@ -0,0 +1,311 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2" // External library used for handling terminal cell views
+	"github.com/rivo/tview"       // External library used for managing terminal-based user interfaces
+
+	httpclient "github.com/SiirRandall/go-restful/internal/httpClient" // HTTP client module
+	"github.com/SiirRandall/go-restful/internal/settings"              // Application wide defaults
+)
+
+// settingsScopes are the two sets of settings the settings dialog can edit.
+var settingsScopes = []string{"Global defaults", "This request"}
+
+// settingsFields holds the items of the settings form, in the order they are shown.
+type settingsFields struct {
+	connectTimeout, readTimeout, writeTimeout *tview.InputField
+	followRedirects                           *tview.DropDown
+	maxRedirects                              *tview.InputField
+	insecure                                  *tview.DropDown
+	caCert, clientCert, clientKey             *tview.InputField
+	proxy                                     *tview.InputField
+}
+
+// addSettingsFields adds every settings field to form. Dropdowns get the given options.
+func addSettingsFields(form *tview.Form, boolOptions []string) *settingsFields {
+	field := func(label string) *tview.InputField {
+		input := tview.NewInputField().SetLabel(label).SetFieldWidth(50)
+		form.AddFormItem(input)
+		return input
+	}
+	dropDown := func(label string) *tview.DropDown {
+		choice := tview.NewDropDown().SetLabel(label).SetOptions(boolOptions, nil).SetCurrentOption(0)
+		form.AddFormItem(choice)
+		return choice
+	}
+	return &settingsFields{
+		connectTimeout:  field("Connect timeout"),
+		readTimeout:     field("Read timeout"),
+		writeTimeout:    field("Write timeout"),
+		followRedirects: dropDown("Follow redirects"),
+		maxRedirects:    field("Max redirects"),
+		insecure:        dropDown("Skip TLS verify"),
+		caCert:          field("CA bundle"),
+		clientCert:      field("Client cert"),
+		clientKey:       field("Client key"),
+		proxy:           field("Proxy"),
+	}
+}
+
+// ShowSettingsModal opens the client settings dialog. The global defaults are saved to the settings
+// file, the overrides of the current request are kept with it and saved with its collection.
+func ShowSettingsModal(app *tview.Application, pages *tview.Pages, forms *RequestForms) {
+	form := tview.NewForm()
+	form.SetBorder(true)
+
+	// save reads the form back for the scope that is shown
+	var save func() error
+
+	controls := tview.NewForm()
+	controls.SetHorizontal(true)
+	controls.AddDropDown("Scope", settingsScopes, 0, func(_ string, index int) {
+		form.Clear(false)
+		if index == 0 {
+			form.SetTitle("Global defaults - empty timeouts mean none")
+			save = globalSettingsForm(form, forms.Session)
+		} else {
+			form.SetTitle("This request - empty fields use the global default")
+			save = requestSettingsForm(form, forms.Session)
+		}
+	})
+
+	form.AddButton("Save", func() {
+		if err := save(); err != nil {
+			LogMessage(forms.Log, fmt.Sprintf("Error saving settings: %v", err))
+			return
+		}
+		LogMessage(forms.Log, "Saved settings")
+		HideModal(pages, "settings")
+	})
+	form.AddButton("Close", func() {
+		HideModal(pages, "settings")
+	})
+	form.SetCancelFunc(func() { HideModal(pages, "settings") })
+	controls.SetCancelFunc(func() { HideModal(pages, "settings") })
+
+	// Tab moves from the scope dropdown into the settings
+	controls.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			app.SetFocus(form)
+			return nil
+		}
+		return event
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(controls, 3, 0, true).
+		AddItem(form, 0, 1, false)
+	layout.SetBorder(true).SetTitle("Settings (Esc to close)")
+
+	ShowModal(pages, "settings", layout, 80, 30)
+	app.SetFocus(controls)
+}
+
+// globalSettingsForm fills form with the global client settings and returns the function saving them.
+func globalSettingsForm(form *tview.Form, session *Session) func() error {
+	current := session.Settings.Client
+	fields := addSettingsFields(form, []string{"No", "Yes"})
+
+	fields.connectTimeout.SetText(durationText(current.ConnectTimeout))
+	fields.readTimeout.SetText(durationText(current.ReadTimeout))
+	fields.writeTimeout.SetText(durationText(current.WriteTimeout))
+	fields.followRedirects.SetCurrentOption(boolOption(current.FollowRedirects))
+	fields.maxRedirects.SetText(strconv.Itoa(current.MaxRedirects))
+	fields.insecure.SetCurrentOption(boolOption(current.InsecureSkipVerify))
+	fields.caCert.SetText(current.CACertFile)
+	fields.clientCert.SetText(current.ClientCertFile)
+	fields.clientKey.SetText(current.ClientKeyFile)
+	fields.proxy.SetText(current.Proxy)
+	fields.proxy.SetPlaceholder("http://host:port or socks5://host:port")
+
+	return func() error {
+		var client httpclient.ClientSettings
+		var err error
+		if client.ConnectTimeout, err = parseDurationField(fields.connectTimeout); err != nil {
+			return err
+		}
+		if client.ReadTimeout, err = parseDurationField(fields.readTimeout); err != nil {
+			return err
+		}
+		if client.WriteTimeout, err = parseDurationField(fields.writeTimeout); err != nil {
+			return err
+		}
+		if text := strings.TrimSpace(fields.maxRedirects.GetText()); text != "" {
+			if client.MaxRedirects, err = strconv.Atoi(text); err != nil {
+				return fmt.Errorf("invalid max redirects %q", text)
+			}
+		}
+		index, _ := fields.followRedirects.GetCurrentOption()
+		client.FollowRedirects = index == 1
+		index, _ = fields.insecure.GetCurrentOption()
+		client.InsecureSkipVerify = index == 1
+		client.CACertFile = strings.TrimSpace(fields.caCert.GetText())
+		client.ClientCertFile = strings.TrimSpace(fields.clientCert.GetText())
+		client.ClientKeyFile = strings.TrimSpace(fields.clientKey.GetText())
+		client.Proxy = strings.TrimSpace(fields.proxy.GetText())
+
+		updated := session.Settings
+		updated.Client = client
+		if err := settings.Save(updated); err != nil {
+			return err
+		}
+		session.Settings = updated
+		return nil
+	}
+}
+
+// requestSettingsForm fills form with the overrides of the current request and returns the function
+// storing them. The global value of every field is shown as its placeholder.
+func requestSettingsForm(form *tview.Form, session *Session) func() error {
+	global := session.Settings.Client
+	fields := addSettingsFields(form, []string{
+		"Default (" + yesNo(global.FollowRedirects) + ")", "Yes", "No",
+	})
+	fields.insecure.SetOptions([]string{"Default (" + yesNo(global.InsecureSkipVerify) + ")", "Yes", "No"}, nil)
+	fields.insecure.SetCurrentOption(0)
+
+	fields.connectTimeout.SetPlaceholder(placeholder(durationText(global.ConnectTimeout)))
+	fields.readTimeout.SetPlaceholder(placeholder(durationText(global.ReadTimeout)))
+	fields.writeTimeout.SetPlaceholder(placeholder(durationText(global.WriteTimeout)))
+	fields.maxRedirects.SetPlaceholder(placeholder(strconv.Itoa(global.MaxRedirects)))
+	fields.caCert.SetPlaceholder(placeholder(global.CACertFile))
+	fields.clientCert.SetPlaceholder(placeholder(global.ClientCertFile))
+	fields.clientKey.SetPlaceholder(placeholder(global.ClientKeyFile))
+	fields.proxy.SetPlaceholder(placeholder(global.Proxy))
+
+	override := &httpclient.SettingsOverride{}
+	if session.Request.Settings != nil {
+		override = session.Request.Settings
+	}
+	setText := func(field *tview.InputField, value *string) {
+		if value != nil {
+			field.SetText(*value)
+		}
+	}
+	setDuration := func(field *tview.InputField, value *httpclient.Duration) {
+		if value != nil {
+			field.SetText(time.Duration(*value).String())
+		}
+	}
+	setBool := func(choice *tview.DropDown, value *bool) {
+		if value != nil {
+			choice.SetCurrentOption(2 - boolOption(*value)) // Yes is option 1, No option 2
+		}
+	}
+	setDuration(fields.connectTimeout, override.ConnectTimeout)
+	setDuration(fields.readTimeout, override.ReadTimeout)
+	setDuration(fields.writeTimeout, override.WriteTimeout)
+	setBool(fields.followRedirects, override.FollowRedirects)
+	if override.MaxRedirects != nil {
+		fields.maxRedirects.SetText(strconv.Itoa(*override.MaxRedirects))
+	}
+	setBool(fields.insecure, override.InsecureSkipVerify)
+	setText(fields.caCert, override.CACertFile)
+	setText(fields.clientCert, override.ClientCertFile)
+	setText(fields.clientKey, override.ClientKeyFile)
+	setText(fields.proxy, override.Proxy)
+
+	return func() error {
+		updated := &httpclient.SettingsOverride{}
+		text := func(field *tview.InputField) *string {
+			value := strings.TrimSpace(field.GetText())
+			if value == "" {
+				return nil
+			}
+			return &value
+		}
+		duration := func(field *tview.InputField) (*httpclient.Duration, error) {
+			if text(field) == nil {
+				return nil, nil
+			}
+			value, err := parseDurationField(field)
+			return &value, err
+		}
+		choice := func(dropDown *tview.DropDown) *bool {
+			index, _ := dropDown.GetCurrentOption()
+			if index <= 0 {
+				return nil
+			}
+			value := index == 1
+			return &value
+		}
+
+		var err error
+		if updated.ConnectTimeout, err = duration(fields.connectTimeout); err != nil {
+			return err
+		}
+		if updated.ReadTimeout, err = duration(fields.readTimeout); err != nil {
+			return err
+		}
+		if updated.WriteTimeout, err = duration(fields.writeTimeout); err != nil {
+			return err
+		}
+		if value := text(fields.maxRedirects); value != nil {
+			hops, err := strconv.Atoi(*value)
+			if err != nil {
+				return fmt.Errorf("invalid max redirects %q", *value)
+			}
+			updated.MaxRedirects = &hops
+		}
+		updated.FollowRedirects = choice(fields.followRedirects)
+		updated.InsecureSkipVerify = choice(fields.insecure)
+		updated.CACertFile = text(fields.caCert)
+		updated.ClientCertFile = text(fields.clientCert)
+		updated.ClientKeyFile = text(fields.clientKey)
+		updated.Proxy = text(fields.proxy)
+
+		// The overrides have no form on the main screen, they are written into the request directly
+		session.Request.Settings = updated
+		if updated.IsEmpty() {
+			session.Request.Settings = nil
+		}
+		return nil
+	}
+}
+
+// parseDurationField parses the duration typed into a settings field.
+func parseDurationField(field *tview.InputField) (httpclient.Duration, error) {
+	d, err := httpclient.ParseDuration(strings.TrimSpace(field.GetText()))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", field.GetLabel(), err)
+	}
+	return httpclient.Duration(d), nil
+}
+
+// durationText shows a duration for editing, zero is left empty.
+func durationText(d httpclient.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return time.Duration(d).String()
+}
+
+// placeholder describes the global value a request falls back to.
+func placeholder(value string) string {
+	if value == "" || value == "0" {
+		return "default: none"
+	}
+	return "default: " + value
+}
+
+// boolOption returns the dropdown index of a boolean in a No/Yes dropdown.
+func boolOption(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// yesNo spells out a boolean.
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,74 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/snippet" // Code snippet generators
+)
+
+// ShowSnippetModal opens a dialog that shows the current request as code in a chosen language
+// and can write the snippet to a file.
+func ShowSnippetModal(app *tview.Application, pages *tview.Pages, forms *RequestForms) {
+	details, err := BuildRequestDetails(forms.Session)
+	if err != nil {
+		LogMessage(forms.Log, err.Error())
+		return
+	}
+
+	// Read-only view for the generated code
+	codeView := tview.NewTextView()
+	codeView.SetScrollable(true)
+	codeView.SetBorder(true)
+
+	controls := tview.NewForm()
+	controls.SetHorizontal(true)
+
+	// Regenerate the snippet whenever another language is picked
+	language := snippet.Languages[0]
+	controls.AddDropDown("Language", snippet.Languages, 0, func(option string, _ int) {
+		language = option
+		code, err := snippet.Generate(language, details)
+		if err != nil {
+			code = err.Error()
+		}
+		codeView.SetText(code).ScrollToBeginning()
+		codeView.SetTitle(language)
+
+		// Suggest a file name matching the language
+		if controls.GetFormItemCount() > 1 {
+			pathField := controls.GetFormItem(1).(*tview.InputField)
+			path := pathField.GetText()
+			pathField.SetText(strings.TrimSuffix(path, filepath.Ext(path)) + snippet.Extensions[language])
+		}
+	})
+	controls.AddInputField("File", "request"+snippet.Extensions[language], 30, nil, nil)
+	controls.AddButton("Save", func() {
+		path := controls.GetFormItem(1).(*tview.InputField).GetText()
+		if err := os.WriteFile(path, []byte(codeView.GetText(false)), 0o644); err != nil {
+			LogMessage(forms.Log, fmt.Sprintf("Error saving snippet: %v", err))
+			return
+		}
+		LogMessage(forms.Log, fmt.Sprintf("Saved %s snippet to %s", language, path))
+		HideModal(pages, "snippet")
+	})
+	controls.AddButton("Close", func() {
+		HideModal(pages, "snippet")
+	})
+	controls.SetCancelFunc(func() {
+		HideModal(pages, "snippet")
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(controls, 3, 0, true).
+		AddItem(codeView, 0, 1, false)
+	layout.SetBorder(true).SetTitle("Code snippet")
+
+	ShowModal(pages, "snippet", layout, 110, 30)
+	app.SetFocus(controls)
+}
@@ -0,0 +1,172 @@
+// Package tui includes functions to display and handle text based User Interface tasks
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview" // External library used for managing terminal-based user interfaces
+
+	"github.com/SiirRandall/go-restful/internal/assertion" // Checks of the response
+	"github.com/SiirRandall/go-restful/internal/extract"   // Values saved from the response into variables
+)
+
+// testsHelp shows the syntax of a check under the rows of the Tests page.
+const testsHelp = "[gray]status == 200\nheader Server exists\n$.data[0].id == 42\n" +
+	"body contains ok\ntime < 500ms\nset token = $.token[-]"
+
+// TestsEditor is the Tests page: one check per row, written as a line such as status == 200 or
+// $.data[0].id == 42, or a value to save into a variable, such as set token = $.token, with a
+// checkbox to switch it off. A row that does not parse gets a red label and the reason under the
+// rows, and is left out of the request until it is fixed.
+type TestsEditor struct {
+	*tview.Form
+
+	rows    []testRow       // Every row, in display order
+	message *tview.TextView // Syntax help, or why the last edited row does not parse
+	changed func()          // Called after a row was edited or switched on or off
+}
+
+// testRow is a row of the Tests page as it was typed.
+type testRow struct {
+	text     string
+	disabled bool
+}
+
+// InitTestsForm initializes the Tests page with an empty row
+func InitTestsForm() *TestsEditor {
+	editor := &TestsEditor{Form: tview.NewForm(), message: tview.NewTextView()}
+	editor.message.SetDynamicColors(true).SetWrap(true).SetSize(6, 0)
+	editor.buildRows()
+	editor.AddButton("Add Check", func() {
+		editor.RemoveFormItem(editor.GetFormItemCount() - 1) // The help goes back under the new row
+		editor.rows = append(editor.rows, testRow{})
+		editor.addRow(len(editor.rows) - 1)
+		editor.AddFormItem(editor.message)
+	})
+	editor.SetBorder(true).
+		SetTitle("[white]Params - Headers - Body - Token - [green]Tests") // Set the title of the Tests form
+
+	return editor
+}
+
+// SetChangedFunc sets a handler called whenever a row is edited or switched on or off.
+func (e *TestsEditor) SetChangedFunc(handler func()) {
+	e.changed = handler
+}
+
+// notify calls the changed handler, if there is one.
+func (e *TestsEditor) notify() {
+	if e.changed != nil {
+		e.changed()
+	}
+}
+
+// Assertions returns the checks of every row that parses, switched off ones included.
+func (e *TestsEditor) Assertions() []assertion.Assertion {
+	var assertions []assertion.Assertion
+	for _, row := range e.rows {
+		if extract.IsRule(row.text) {
+			continue
+		}
+		if a, err := assertion.Parse(row.text); err == nil {
+			a.Disabled = row.disabled
+			assertions = append(assertions, a)
+		}
+	}
+	return assertions
+}
+
+// Extractions returns the rules of every set row that parses, switched off ones included.
+func (e *TestsEditor) Extractions() []extract.Rule {
+	var rules []extract.Rule
+	for _, row := range e.rows {
+		if !extract.IsRule(row.text) {
+			continue
+		}
+		if r, err := extract.Parse(row.text); err == nil {
+			r.Disabled = row.disabled
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// Load shows the checks of a request, one per row, followed by the values it saves.
+func (e *TestsEditor) Load(assertions []assertion.Assertion, rules []extract.Rule) {
+	e.rows = nil
+	for _, a := range assertions {
+		e.rows = append(e.rows, testRow{text: a.String(), disabled: a.Disabled})
+	}
+	for _, r := range rules {
+		e.rows = append(e.rows, testRow{text: r.String(), disabled: r.Disabled})
+	}
+	e.buildRows()
+}
+
+// buildRows replaces the items of the page with one row per check, and an empty row when there are
+// none, followed by the help.
+func (e *TestsEditor) buildRows() {
+	e.Clear(false)
+	if len(e.rows) == 0 {
+		e.rows = append(e.rows, testRow{})
+	}
+	for i := range e.rows {
+		e.addRow(i)
+	}
+	e.message.SetText(testsHelp)
+	e.AddFormItem(e.message)
+}
+
+// addRow adds the items of row i: a checkbox running the check or not, and the check itself.
+func (e *TestsEditor) addRow(i int) {
+	row := e.rows[i]
+	e.AddCheckbox("┌Run", !row.disabled, func(checked bool) {
+		e.rows[i].disabled = !checked
+		e.notify()
+	})
+	field := tview.NewInputField().SetText(row.text).SetFieldWidth(50)
+	field.SetLabel(e.label(i, row.text))
+	field.SetChangedFunc(func(text string) {
+		e.rows[i].text = text
+		field.SetLabel(e.label(i, text))
+		if err := parseRow(text); err != nil {
+			e.message.SetText(fmt.Sprintf("[red]%s: %s[-]", rowName(i, text), tview.Escape(err.Error())))
+		} else {
+			e.message.SetText(testsHelp)
+		}
+		e.notify()
+	})
+	e.AddFormItem(field)
+}
+
+// label returns the label of row i, Set for a value saved into a variable, red when its text does
+// not parse.
+func (e *TestsEditor) label(i int, text string) string {
+	label := "└" + rowName(i, text)
+	if parseRow(text) != nil {
+		return "[red]" + label + "[-]"
+	}
+	return label
+}
+
+// rowName names row i after what its text is: Check n, or Set n for a value saved into a variable.
+func rowName(i int, text string) string {
+	if extract.IsRule(text) {
+		return fmt.Sprintf("Set %d", i+1)
+	}
+	return fmt.Sprintf("Check %d", i+1)
+}
+
+// parseRow reports why the text of a row is neither a check nor a set rule, nil for an empty row.
+func parseRow(text string) error {
+	var err error
+	switch {
+	case strings.TrimSpace(text) == "":
+	case extract.IsRule(text):
+		_, err = extract.Parse(text)
+	default:
+		_, err = assertion.Parse(text)
+	}
+	return err
+}
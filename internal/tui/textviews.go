@@ -5,16 +5,36 @@ import (
 	"github.com/rivo/tview" // Importing the library that provides tools for building rich terminal applications
 )
 
-// InitJsonViewer initializes a new scrollable text view with dynamic colors,
-// a border, a title and disables text wrapping. It's designed to display JSON data.
-func InitJsonViewer() *ScrollTextView {
-	textView := NewScrollTextView()  // Creating new scrollable text view instance
-	textView.SetDynamicColors(true)  // Enabling dynamic colors to display different info levels
-	textView.SetBorder(true)         // Adding border around the text view
-	textView.SetTitle("JSON Viewer") // Setting title for text view
-	textView.SetWrap(false)          // Disabling text wrapping for better JSON formatting
-
-	return textView // Returns the configured text view
+// InitJsonViewer initializes the response viewer: a JSON tree for JSON bodies, a table for CSV
+// bodies and a scrollable text view, without wrapping, for anything else, above a filter input for
+// JSON bodies.
+func InitJsonViewer() *ResponseViewer {
+	textView := NewScrollTextView() // Creating new scrollable text view instance
+	textView.SetDynamicColors(true) // Enabling dynamic colors to display different info levels
+	textView.SetWrap(false)         // Disabling text wrapping for better formatting
+
+	viewer := &ResponseViewer{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		Text:   textView,
+		Tree:   NewJSONTree(),
+		Table:  tview.NewTable(),
+		pages:  tview.NewPages(),
+		prompt: tview.NewInputField(),
+		filter: tview.NewInputField().SetLabel("Filter: "),
+	}
+	viewer.Table.SetFixed(1, 0).SetSelectable(true, false).SetSeparator(tview.Borders.Vertical)
+	viewer.filter.SetPlaceholder("JSONPath ($.data[*].id) or jq (.data[].id), Enter applies")
+	viewer.pages.
+		AddPage(responseTextPage, viewer.Text, true, true).
+		AddPage(responseTreePage, viewer.Tree, true, false).
+		AddPage(responseTablePage, viewer.Table, true, false)
+	viewer.AddItem(viewer.pages, 0, 1, true).
+		AddItem(viewer.prompt, 0, 0, false). // Zero height until a search or jump is started
+		AddItem(viewer.filter, 1, 0, false)
+	viewer.SetBorder(true)         // Adding border around the viewer
+	viewer.SetTitle("JSON Viewer") // Setting title for the viewer
+
+	return viewer // Returns the configured viewer
 }
 
 // InitLogView initializes a new scrollable text view with dynamic colors,
@@ -30,13 +50,13 @@ func InitLogView() *tview.TextView {
 }
 
 // InitDetailsView initializes a new scrollable text view with dynamic colors,
-// no border, and a title. It's designed to provide more detailed information on screen.
+// a border, and a title. It's designed to show the status, timing and headers of a response.
 func InitDetailsView() *tview.TextView {
-	detailsView := tview.NewTextView() // Creating new text view instance
-	detailsView.SetDynamicColors(true) // Enabling dynamic colors to display details in visually differentiated manner
-	detailsView.SetScrollable(true)    // Making the text view scrollable
-	detailsView.SetBorder(false)       // No border for this view
-	detailsView.SetTitle("Details")    // Setting title for text view
+	detailsView := tview.NewTextView()       // Creating new text view instance
+	detailsView.SetDynamicColors(true)       // Enabling dynamic colors to display details in visually differentiated manner
+	detailsView.SetScrollable(true)          // Making the text view scrollable
+	detailsView.SetBorder(true)              // Adding border around the text view
+	detailsView.SetTitle("Response Details") // Setting title for text view
 
 	return detailsView // Returns the configured text view
 }
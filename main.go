@@ -1,38 +1,45 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
 
-	"github.com/rivo/tview" // Importing the tview package for terminal-based UI applications
+	"github.com/gdamore/tcell/v2" // Importing the tcell package for key definitions
+	"github.com/rivo/tview"       // Importing the tview package for terminal-based UI applications
 
-	"github.com/SiirRandall/go-restful/internal/input" // Importing internal packages for handling inputs
-	"github.com/SiirRandall/go-restful/internal/tui"   // Importing internal packages for text UI creation
+	"github.com/SiirRandall/go-restful/internal/cli"      // Importing internal packages for the commands run without the UI
+	"github.com/SiirRandall/go-restful/internal/input"    // Importing internal packages for handling inputs
+	"github.com/SiirRandall/go-restful/internal/settings" // Importing internal packages for the application settings
+	"github.com/SiirRandall/go-restful/internal/tui"      // Importing internal packages for text UI creation
 )
 
 func main() {
+	// Subcommands such as 'run' work without the UI, for scripts and CI.
+	if len(os.Args) > 1 {
+		os.Exit(cli.Main(os.Args[1:]))
+	}
+
 	// Initializing our app as a new tview Application.
 	app := tview.NewApplication()
 
 	// Enabling mouse support in the app.
 	app.EnableMouse(true)
 
-	// Initialize a form that will hold the parameters for HTTP requests.
-	paramsForm := tview.NewForm()
-
 	// Initialize the log view which displays all the logs in the application.
 	logView := tui.InitLogView()
 
-	// Initialize a form that displays details of requests and responses.
-	detailsForm := tui.InitDetailsForm()
+	// Initialize the page that will hold the parameters for HTTP requests.
+	paramsForm := tui.NewParamsEditor()
 
 	// Initialize URL form which is used to get URL from user.
-	urlForm := tui.InitURLForm(app, paramsForm, logView)
+	urlForm := tui.InitURLForm(paramsForm)
 
 	// Initialize parameter form which is used to get request parameters from user.
-	paramsForm = tui.InitParamsForm(paramsForm, urlForm, logView)
+	paramsForm = tui.InitParamsForm(paramsForm, urlForm)
 
 	// Initialize form used to get request headers from user.
-	headersForm := tui.InitHeadersForm(logView)
+	headersForm := tui.InitHeadersForm()
 
 	// Initialize form used to input the body of an HTTP request.
 	bodyForm := tui.InitBodyForm()
@@ -40,8 +47,11 @@ func main() {
 	// Initialize form used to get token for authentication.
 	tokenForm := tui.InitTokenForm()
 
+	// Initialize form used to write the checks run against the response.
+	testsForm := tui.InitTestsForm()
+
 	// Initialize the pages rendered on HTML.
-	htmlPages := tui.InitHTMLPages(paramsForm, headersForm, bodyForm, tokenForm, logView)
+	htmlPages := tui.InitHTMLPages(paramsForm.Form, headersForm.Form, bodyForm.Form, tokenForm.Form, testsForm.Form, logView)
 
 	// Initialises the JSON viewer which shows pretty-printed JSON structures.
 	textView := tui.InitJsonViewer()
@@ -49,33 +59,72 @@ func main() {
 	// Initialize a view to display request and response details.
 	detailsView := tui.InitDetailsView()
 
+	// Bundle the forms that describe the request being edited.
+	forms := &tui.RequestForms{
+		URL:     urlForm,
+		Params:  paramsForm,
+		Headers: headersForm,
+		Body:    bodyForm,
+		Token:   tokenForm,
+		Tests:   testsForm,
+		Log:     logView,
+		Viewer:  textView,
+		Details: detailsView,
+	}
+
+	// Load the application wide settings, falling back to the defaults.
+	defaults, err := settings.Load()
+	if err != nil {
+		tui.LogMessage(logView, fmt.Sprintf("Error loading settings: %v", err))
+	}
+
+	// Wrap everything in pages so dialogs can be shown on top of the main layout.
+	pages := tview.NewPages()
+
 	// Initialize components related to the URL input & buttons for different actions.
-	methodbox, buttonPanel := tui.InitUrlComponents(
-		app,
-		urlForm,
-		detailsForm,
-		logView,
-		textView,
-		detailsView,
-	)
+	methodbox, buttonPanel := tui.InitUrlComponents(app, pages, forms)
+
+	// Bind the forms to the session, which holds the request being edited and everything it is sent with.
+	forms.Bind(tui.NewSession(defaults))
+
+	// Initialize the environment dropdown shown next to the method dropdown.
+	environments := tui.InitEnvironmentBox(forms)
 
 	// Integrates and initializes Url input field and associated action buttons.
-	urlAndButtons := tui.InitUrlandButtons(methodbox, urlForm, buttonPanel)
+	urlAndButtons := tui.InitUrlandButtons(methodbox.Form, environments.Form, urlForm, buttonPanel)
 
 	// Initializes the main grid layout with the elements for displaying http request, response and other details.
-	grid := tui.InitGrid(urlAndButtons, htmlPages, textView, detailsForm)
+	grid := tui.InitGrid(urlAndButtons, htmlPages, textView, detailsView)
 
 	// Captures mouse interaction within the TextView component.
-	input.TextViewMouseCapture(logView, textView, headersForm)
+	input.TextViewMouseCapture(logView, textView, headersForm.Form)
 
 	// Captures keyboard and mouse input for the URL form.
-	input.UrlInputCapture(logView, urlForm, detailsForm, textView, detailsView, methodbox)
+	input.UrlInputCapture(app, forms)
 
 	// Captures keyboard and mouse input for the TextView.
-	input.TextViewKBCapture(app, textView, logView, detailsForm, grid)
+	input.TextViewKBCapture(app, forms, grid)
+
+	// Initialize the collections sidebar, hidden until toggled with Ctrl+B.
+	collections := tui.InitCollectionsBrowser(pages, forms)
+	layout := tui.InitMainLayout(collections.Tree, grid)
+	pages.AddPage("main", layout, true, true)
+
+	// Application wide shortcuts.
+	input.AppKBCapture(app, map[tcell.Key]func(){
+		tcell.KeyCtrlB: func() { tui.ToggleSidebar(app, layout, collections.Tree, 35) },
+		tcell.KeyCtrlS: collections.SaveCurrent,
+		tcell.KeyCtrlG: func() { tui.ShowSnippetModal(app, pages, forms) },
+		tcell.KeyCtrlP: func() { tui.ShowCurlImportModal(app, pages, forms) },
+		tcell.KeyCtrlN: func() { tui.ShowEnvironmentsModal(app, pages, environments) },
+		tcell.KeyCtrlX: func() { tui.CancelRequest(forms) },
+		tcell.KeyCtrlO: func() { tui.ShowSettingsModal(app, pages, forms) },
+		tcell.KeyCtrlR: func() { tui.ShowHistoryModal(app, pages, forms) },
+		tcell.KeyCtrlT: func() { tui.ShowCookiesModal(app, pages, forms) },
+	})
 
 	// Run the application - setting the root element and make it full screen. Exits if there are errors.
-	if err := app.SetRoot(grid, true).Run(); err != nil {
+	if err := app.SetRoot(pages, true).Run(); err != nil {
 		log.Fatalf(
 			"Failed to run application: %v",
 			err,